@@ -0,0 +1,408 @@
+package thruk
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var hostStates = map[string]int{"UP": 0, "DOWN": 1, "UNREACHABLE": 2}
+var serviceStates = map[string]int{"OK": 0, "WARNING": 1, "CRITICAL": 2, "UNKNOWN": 3}
+
+type AvailabilityOptions struct {
+	Start time.Time
+	End   time.Time
+	// Timeperiod restricts the report to the times inside it, 24x7 when nil.
+	Timeperiod *TimeperiodEvaluator
+	// Objects limits the report to the given hosts and services. A ref
+	// without Service stands for the host itself. Listed objects without
+	// any history are reported as well. All objects found in the history
+	// are reported when empty.
+	Objects []ServiceRef
+	// AssumeInitialState uses InitialHostState and InitialServiceState for
+	// the time before the first known state instead of counting it as
+	// undetermined.
+	AssumeInitialState  bool
+	InitialHostState    int
+	InitialServiceState int
+	IncludeSoftStates   bool
+	// AvailableStates are the states counted as available, OK and UP when
+	// empty.
+	AvailableStates []int
+	// CountDowntimeAsAvailable counts all time in scheduled downtime as
+	// available regardless of the state.
+	CountDowntimeAsAvailable bool
+	// Lookback is how far before Start Availability reads the log to find
+	// the state at Start. Defaults to a day, which covers the state dump
+	// the core writes on every log rotation.
+	Lookback time.Duration
+}
+
+type AvailabilityResult struct {
+	HostName           string
+	ServiceDescription string
+	// Total is the part of the report window inside the report timeperiod.
+	Total time.Duration
+	// Unscheduled and Scheduled hold the time spent in each state outside
+	// and inside of scheduled downtimes.
+	Unscheduled  map[int]time.Duration
+	Scheduled    map[int]time.Duration
+	Undetermined time.Duration
+	// Availability is the available share of the known time in percent, 0
+	// when nothing is known.
+	Availability float64
+}
+
+// Time returns the time spent in state, in and outside of downtimes.
+func (r AvailabilityResult) Time(state int) time.Duration {
+	return r.Unscheduled[state] + r.Scheduled[state]
+}
+
+// Percent returns the share of the known time spent in state.
+func (r AvailabilityResult) Percent(state int) float64 {
+	known := r.Total - r.Undetermined
+	if known <= 0 {
+		return 0
+	}
+	return 100 * float64(r.Time(state)) / float64(known)
+}
+
+func (r AvailabilityResult) MarshalJSON() ([]byte, error) {
+	seconds := func(durations map[int]time.Duration) map[string]float64 {
+		converted := map[string]float64{}
+		for state, duration := range durations {
+			converted[strconv.Itoa(state)] = duration.Seconds()
+		}
+		return converted
+	}
+	return json.Marshal(struct {
+		HostName           string             `json:"host_name"`
+		ServiceDescription string             `json:"service_description,omitempty"`
+		Total              float64            `json:"total"`
+		Unscheduled        map[string]float64 `json:"unscheduled"`
+		Scheduled          map[string]float64 `json:"scheduled"`
+		Undetermined       float64            `json:"undetermined"`
+		Availability       float64            `json:"availability"`
+	}{r.HostName, r.ServiceDescription, r.Total.Seconds(), seconds(r.Unscheduled), seconds(r.Scheduled), r.Undetermined.Seconds(), r.Availability})
+}
+
+// WriteAvailabilityCSV writes one row per result. Durations are in seconds
+// and include the time in scheduled downtime, which is repeated in the
+// scheduled column.
+func WriteAvailabilityCSV(w io.Writer, results []AvailabilityResult) error {
+	writer := csv.NewWriter(w)
+	header := []string{"host_name", "service_description", "availability", "total", "undetermined", "scheduled", "state_0", "state_1", "state_2", "state_3"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	format := func(d time.Duration) string {
+		return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+	}
+	for _, r := range results {
+		var scheduled time.Duration
+		for _, d := range r.Scheduled {
+			scheduled += d
+		}
+		row := []string{
+			r.HostName,
+			r.ServiceDescription,
+			strconv.FormatFloat(r.Availability, 'f', 3, 64),
+			format(r.Total),
+			format(r.Undetermined),
+			format(scheduled),
+		}
+		for state := 0; state <= 3; state++ {
+			row = append(row, format(r.Time(state)))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+type availabilityTracker struct {
+	result *AvailabilityResult
+	known  bool
+	state  int
+	// downtime is set while the object or its host is in downtime,
+	// ownDowntime only for downtimes of the object itself.
+	downtime    bool
+	ownDowntime bool
+	since       time.Time
+}
+
+// availabilityCalculator replays the log in chronological order and adds
+// up how long every object spent in each state.
+type availabilityCalculator struct {
+	opts          AvailabilityOptions
+	periods       [][2]time.Time
+	trackers      map[ServiceRef]*availabilityTracker
+	order         []ServiceRef
+	fixed         bool
+	hostDowntimes map[string]bool
+}
+
+func newAvailabilityCalculator(opts AvailabilityOptions) (*availabilityCalculator, error) {
+	if opts.Start.IsZero() || !opts.End.After(opts.Start) {
+		return nil, ErrorInvalidInput
+	}
+	if len(opts.AvailableStates) == 0 {
+		opts.AvailableStates = []int{0}
+	}
+	c := &availabilityCalculator{
+		opts:          opts,
+		trackers:      map[ServiceRef]*availabilityTracker{},
+		hostDowntimes: map[string]bool{},
+	}
+
+	if opts.Timeperiod == nil {
+		c.periods = [][2]time.Time{{opts.Start, opts.End}}
+	} else {
+		from := opts.Start
+		inside := opts.Timeperiod.Contains(from)
+		for from.Before(opts.End) {
+			next, ok := opts.Timeperiod.NextTransition(from)
+			if !ok || next.After(opts.End) {
+				next = opts.End
+			}
+			if inside {
+				c.periods = append(c.periods, [2]time.Time{from, next})
+			}
+			from, inside = next, !inside
+		}
+	}
+
+	for _, ref := range opts.Objects {
+		c.tracker(ref)
+	}
+	c.fixed = len(opts.Objects) > 0
+	return c, nil
+}
+
+// reportTime returns the part of [from, to) inside the report window and
+// timeperiod.
+func (c *availabilityCalculator) reportTime(from, to time.Time) time.Duration {
+	var total time.Duration
+	for _, period := range c.periods {
+		start, end := period[0], period[1]
+		if from.After(start) {
+			start = from
+		}
+		if to.Before(end) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+func (c *availabilityCalculator) tracker(ref ServiceRef) *availabilityTracker {
+	if tracker, ok := c.trackers[ref]; ok {
+		return tracker
+	}
+	if c.fixed {
+		return nil
+	}
+	tracker := &availabilityTracker{
+		result: &AvailabilityResult{
+			HostName:           ref.Host,
+			ServiceDescription: ref.Service,
+			Unscheduled:        map[int]time.Duration{},
+			Scheduled:          map[int]time.Duration{},
+		},
+		downtime: c.hostDowntimes[ref.Host],
+		since:    c.opts.Start,
+	}
+	if c.opts.AssumeInitialState {
+		tracker.known = true
+		tracker.state = c.opts.InitialHostState
+		if ref.Service != "" {
+			tracker.state = c.opts.InitialServiceState
+		}
+	}
+	c.trackers[ref] = tracker
+	c.order = append(c.order, ref)
+	return tracker
+}
+
+// advance books the time since the last change to the tracker's current
+// state.
+func (c *availabilityCalculator) advance(tracker *availabilityTracker, to time.Time) {
+	if !to.After(tracker.since) {
+		return
+	}
+	duration := c.reportTime(tracker.since, to)
+	tracker.since = to
+	result := tracker.result
+	result.Total += duration
+	switch {
+	case !tracker.known:
+		result.Undetermined += duration
+	case tracker.downtime:
+		result.Scheduled[tracker.state] += duration
+	default:
+		result.Unscheduled[tracker.state] += duration
+	}
+}
+
+// logFields splits the part of a log message after "<TYPE>: " at
+// semicolons into at most n fields.
+func logFields(e LogEntry, n int) []string {
+	message := e.Message
+	if i := strings.Index(message, e.Type+": "); i >= 0 {
+		message = message[i+len(e.Type)+2:]
+	} else if e.HostName != "" {
+		return nil
+	}
+	return strings.SplitN(message, ";", n)
+}
+
+func (c *availabilityCalculator) add(e LogEntry) {
+	at := e.Timestamp()
+	isService := strings.Contains(e.Type, "SERVICE")
+	fields := 2
+	if isService {
+		fields = 3
+	}
+
+	switch e.Kind() {
+	case LogAlert, LogState:
+		ref, state, stateType := ServiceRef{Host: e.HostName, Service: e.ServiceDescription}, e.State, e.StateType
+		if parsed := logFields(e, fields+2); len(parsed) > fields {
+			states := hostStates
+			if isService {
+				states = serviceStates
+				ref.Service = parsed[1]
+			}
+			ref.Host = parsed[0]
+			if value, ok := states[parsed[fields-1]]; ok {
+				state = value
+			}
+			stateType = parsed[fields]
+		}
+		if e.Kind() == LogAlert && stateType == "SOFT" && !c.opts.IncludeSoftStates {
+			return
+		}
+		if tracker := c.tracker(ref); tracker != nil {
+			c.advance(tracker, at)
+			tracker.known, tracker.state = true, state
+		}
+	case LogDowntime:
+		ref := ServiceRef{Host: e.HostName, Service: e.ServiceDescription}
+		var event string
+		if parsed := logFields(e, fields+1); len(parsed) >= fields {
+			ref.Host, event = parsed[0], parsed[fields-1]
+			if isService {
+				ref.Service = parsed[1]
+			}
+		} else {
+			event = strings.ToUpper(e.Message)
+		}
+		started := strings.Contains(event, "STARTED")
+		if !isService {
+			c.hostDowntimes[ref.Host] = started
+			for _, other := range c.order {
+				if other.Host == ref.Host {
+					tracker := c.trackers[other]
+					c.advance(tracker, at)
+					tracker.downtime = started || tracker.ownDowntime
+				}
+			}
+			return
+		}
+		if tracker := c.tracker(ref); tracker != nil {
+			c.advance(tracker, at)
+			tracker.ownDowntime = started
+			tracker.downtime = started || c.hostDowntimes[ref.Host]
+		}
+	}
+}
+
+func (c *availabilityCalculator) results() []AvailabilityResult {
+	results := make([]AvailabilityResult, 0, len(c.order))
+	for _, ref := range c.order {
+		tracker := c.trackers[ref]
+		c.advance(tracker, c.opts.End)
+		result := *tracker.result
+		if known := result.Total - result.Undetermined; known > 0 {
+			var available time.Duration
+			for state, duration := range result.Unscheduled {
+				if containsState(c.opts.AvailableStates, state) {
+					available += duration
+				}
+			}
+			for state, duration := range result.Scheduled {
+				if c.opts.CountDowntimeAsAvailable || containsState(c.opts.AvailableStates, state) {
+					available += duration
+				}
+			}
+			result.Availability = 100 * float64(available) / float64(known)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func containsState(states []int, state int) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// CalculateAvailability computes the availability of hosts and services
+// from their log history. Entries before opts.Start only establish the
+// state at the start of the window, so the history should reach back far
+// enough to contain it.
+func CalculateAvailability(entries []LogEntry, opts AvailabilityOptions) ([]AvailabilityResult, error) {
+	c, err := newAvailabilityCalculator(opts)
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]LogEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+	for _, e := range sorted {
+		if e.Time >= opts.End.Unix() {
+			break
+		}
+		c.add(e)
+	}
+	return c.results(), nil
+}
+
+// Availability computes the availability like CalculateAvailability and
+// streams the history from the log.
+func (t Thruk) Availability(ctx context.Context, opts AvailabilityOptions) ([]AvailabilityResult, error) {
+	c, err := newAvailabilityCalculator(opts)
+	if err != nil {
+		return nil, err
+	}
+	lookback := opts.Lookback
+	if lookback == 0 {
+		lookback = 24 * time.Hour
+	}
+	it := t.IterateLogs(ctx, LogFilter{
+		Start: opts.Start.Add(-lookback),
+		End:   opts.End,
+		Kinds: []LogKind{LogAlert, LogState, LogDowntime},
+	})
+	for it.Next() {
+		c.add(it.Entry())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return c.results(), nil
+}
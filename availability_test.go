@@ -0,0 +1,149 @@
+package thruk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+func logLine(when string, typ, fields string) LogEntry {
+	return LogEntry{Time: at(when).Unix(), Type: typ, Message: "[0] " + typ + ": " + fields}
+}
+
+func Test_CalculateAvailability(t *testing.T) {
+	day := AvailabilityOptions{Start: at("2026-10-19 00:00"), End: at("2026-10-20 00:00")}
+
+	t.Run("Time in each state is summed up from alerts", func(t *testing.T) {
+		results, err := CalculateAvailability([]LogEntry{
+			logLine("2026-10-18 00:00", "CURRENT SERVICE STATE", "web01;HTTP;OK;HARD;1;ok"),
+			logLine("2026-10-19 06:00", "SERVICE ALERT", "web01;HTTP;CRITICAL;SOFT;1;down"),
+			logLine("2026-10-19 06:05", "SERVICE ALERT", "web01;HTTP;CRITICAL;HARD;3;down"),
+			logLine("2026-10-19 12:05", "SERVICE ALERT", "web01;HTTP;OK;HARD;1;ok"),
+		}, day)
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(results), 1)
+		r := results[0]
+		assert.Equal(t, r.HostName, "web01")
+		assert.Equal(t, r.ServiceDescription, "HTTP")
+		assert.Equal(t, r.Total, 24*time.Hour)
+		assert.Equal(t, r.Time(2), 6*time.Hour)
+		assert.Equal(t, r.Time(0), 18*time.Hour)
+		assert.Equal(t, r.Availability, 75.0)
+	})
+	t.Run("Soft states are counted when requested", func(t *testing.T) {
+		opts := day
+		opts.IncludeSoftStates = true
+		results, err := CalculateAvailability([]LogEntry{
+			logLine("2026-10-18 00:00", "CURRENT SERVICE STATE", "web01;HTTP;OK;HARD;1;ok"),
+			logLine("2026-10-19 06:00", "SERVICE ALERT", "web01;HTTP;CRITICAL;SOFT;1;down"),
+			logLine("2026-10-19 12:00", "SERVICE ALERT", "web01;HTTP;OK;HARD;1;ok"),
+		}, opts)
+		assert.NilError(t, err)
+		assert.Equal(t, results[0].Time(2), 6*time.Hour)
+	})
+	t.Run("Time before the first state is undetermined unless assumed", func(t *testing.T) {
+		entries := []LogEntry{logLine("2026-10-19 06:00", "HOST ALERT", "web01;DOWN;HARD;1;down")}
+
+		results, err := CalculateAvailability(entries, day)
+		assert.NilError(t, err)
+		assert.Equal(t, results[0].Undetermined, 6*time.Hour)
+		assert.Equal(t, results[0].Availability, 0.0)
+
+		opts := day
+		opts.AssumeInitialState = true
+		results, err = CalculateAvailability(entries, opts)
+		assert.NilError(t, err)
+		assert.Equal(t, results[0].Undetermined, time.Duration(0))
+		assert.Equal(t, results[0].Availability, 25.0)
+	})
+	t.Run("Host downtimes apply to the services of the host", func(t *testing.T) {
+		opts := day
+		opts.CountDowntimeAsAvailable = true
+		results, err := CalculateAvailability([]LogEntry{
+			logLine("2026-10-18 00:00", "CURRENT HOST STATE", "web01;UP;HARD;1;ok"),
+			logLine("2026-10-18 00:00", "CURRENT SERVICE STATE", "web01;HTTP;OK;HARD;1;ok"),
+			logLine("2026-10-19 02:00", "HOST DOWNTIME ALERT", "web01;STARTED;maintenance"),
+			logLine("2026-10-19 02:10", "HOST ALERT", "web01;DOWN;HARD;1;down"),
+			logLine("2026-10-19 02:10", "SERVICE ALERT", "web01;HTTP;CRITICAL;HARD;1;down"),
+			logLine("2026-10-19 03:50", "HOST ALERT", "web01;UP;HARD;1;ok"),
+			logLine("2026-10-19 03:50", "SERVICE ALERT", "web01;HTTP;OK;HARD;1;ok"),
+			logLine("2026-10-19 04:00", "HOST DOWNTIME ALERT", "web01;STOPPED;maintenance"),
+		}, opts)
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(results), 2)
+		for _, r := range results {
+			assert.Equal(t, r.Scheduled[0], 20*time.Minute)
+			assert.Equal(t, r.Unscheduled[0], 22*time.Hour)
+			assert.Equal(t, r.Availability, 100.0)
+		}
+		assert.Equal(t, results[0].Scheduled[1], 100*time.Minute)
+		assert.Equal(t, results[1].Scheduled[2], 100*time.Minute)
+	})
+	t.Run("Report timeperiod limits the counted time", func(t *testing.T) {
+		opts := day
+		opts.Timeperiod = newTestEvaluator(t, workhours)
+		results, err := CalculateAvailability([]LogEntry{
+			logLine("2026-10-18 00:00", "CURRENT HOST STATE", "web01;UP;HARD;1;ok"),
+			logLine("2026-10-19 16:00", "HOST ALERT", "web01;DOWN;HARD;1;down"),
+			logLine("2026-10-19 20:00", "HOST ALERT", "web01;UP;HARD;1;ok"),
+		}, opts)
+		assert.NilError(t, err)
+
+		assert.Equal(t, results[0].Total, 8*time.Hour)
+		assert.Equal(t, results[0].Time(1), time.Hour)
+		assert.Equal(t, results[0].Availability, 87.5)
+	})
+	t.Run("Listed objects are reported even without history", func(t *testing.T) {
+		opts := day
+		opts.Objects = []ServiceRef{{Host: "db01"}}
+		results, err := CalculateAvailability([]LogEntry{
+			logLine("2026-10-19 06:00", "HOST ALERT", "web01;DOWN;HARD;1;down"),
+		}, opts)
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(results), 1)
+		assert.Equal(t, results[0].HostName, "db01")
+		assert.Equal(t, results[0].Undetermined, 24*time.Hour)
+	})
+	t.Run("Invalid window returns error", func(t *testing.T) {
+		_, err := CalculateAvailability(nil, AvailabilityOptions{Start: at("2026-10-20 00:00"), End: at("2026-10-19 00:00")})
+		assert.Equal(t, err, ErrorInvalidInput)
+	})
+}
+
+func Test_AvailabilityResult_output(t *testing.T) {
+	result := AvailabilityResult{
+		HostName:           "web01",
+		ServiceDescription: "HTTP",
+		Total:              time.Hour,
+		Unscheduled:        map[int]time.Duration{0: 45 * time.Minute, 2: 15 * time.Minute},
+		Scheduled:          map[int]time.Duration{},
+		Availability:       75,
+	}
+
+	t.Run("JSON durations are in seconds", func(t *testing.T) {
+		encoded, err := json.Marshal(result)
+		assert.NilError(t, err)
+		assert.Equal(t, string(encoded), `{"host_name":"web01","service_description":"HTTP","total":3600,"unscheduled":{"0":2700,"2":900},"scheduled":{},"undetermined":0,"availability":75}`)
+	})
+	t.Run("CSV has one row per result", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NilError(t, WriteAvailabilityCSV(&buf, []AvailabilityResult{result}))
+		assert.Equal(t, buf.String(), "host_name,service_description,availability,total,undetermined,scheduled,state_0,state_1,state_2,state_3\n"+
+			"web01,HTTP,75.000,3600,0,0,2700,0,900,0\n")
+	})
+}
+
+func Test_thruk_client_Availability(t *testing.T) {
+	t.Run("Availability of the last hour is computed from the log", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.Availability(context.Background(), AvailabilityOptions{Start: time.Now().Add(-time.Hour), End: time.Now()})
+		assert.NilError(t, err)
+	})
+}
@@ -0,0 +1,446 @@
+package thruk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	StateOK       = 0
+	StateWarning  = 1
+	StateCritical = 2
+	StateUnknown  = 3
+)
+
+// BPFunction is the function of a business process node, e.g.
+// at_least('2', '1') is encoded as {Name: "at_least", Args: ["2", "1"]}.
+// Any of Thruk's functions can be stored; the local evaluator supports
+// status, fixed, worst, best, at_least and not_more. Thruk has no plain not
+// function; its negated counting function is not_more, see BPNotMore.
+type BPFunction struct {
+	Name string
+	Args []string
+}
+
+func BPWorst() BPFunction {
+	return BPFunction{Name: "worst"}
+}
+
+func BPBest() BPFunction {
+	return BPFunction{Name: "best"}
+}
+
+// BPAtLeast is OK while at least warning children are OK and WARNING while
+// at least critical children are. Thresholds may be percentages like "50%".
+func BPAtLeast(warning, critical string) BPFunction {
+	return BPFunction{Name: "at_least", Args: []string{warning, critical}}
+}
+
+// BPNotMore is the inverse of BPAtLeast: it counts the children that are
+// not OK and turns WARNING or CRITICAL when there are more than warning or
+// critical of them. It stands in for a not function, which Thruk does not
+// have.
+func BPNotMore(warning, critical string) BPFunction {
+	return BPFunction{Name: "not_more", Args: []string{warning, critical}}
+}
+
+// BPStatus takes the state of a host, or of a service if service is set.
+func BPStatus(host, service string) BPFunction {
+	return BPFunction{Name: "status", Args: []string{host, service, "="}}
+}
+
+// BPFixed always has the given state, e.g. "OK" or "CRITICAL".
+func BPFixed(state string) BPFunction {
+	return BPFunction{Name: "fixed", Args: []string{state}}
+}
+
+func (f BPFunction) String() string {
+	args := make([]string, len(f.Args))
+	for i, arg := range f.Args {
+		args[i] = "'" + strings.Replace(arg, "'", "\\'", -1) + "'"
+	}
+	return f.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// ParseBPFunction parses functions in the notation used by Thruk, like
+// status('host', 'service', '=').
+func ParseBPFunction(text string) (BPFunction, error) {
+	text = strings.TrimSpace(text)
+	open := strings.Index(text, "(")
+	if open <= 0 || !strings.HasSuffix(text, ")") {
+		return BPFunction{}, fmt.Errorf("invalid business process function %q", text)
+	}
+	f := BPFunction{Name: strings.TrimSpace(text[:open])}
+	inner := text[open+1 : len(text)-1]
+
+	var arg strings.Builder
+	var quote rune
+	quoted, escaped := false, false
+	flush := func() {
+		value := arg.String()
+		if !quoted {
+			value = strings.TrimSpace(value)
+		}
+		f.Args = append(f.Args, value)
+		arg.Reset()
+		quoted = false
+	}
+	for _, r := range inner {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case quote != 0 && r == '\\':
+			escaped = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, quoted = r, true
+			arg.Reset()
+		case r == ',':
+			flush()
+		case r == ' ' && quoted:
+		default:
+			arg.WriteRune(r)
+		}
+	}
+	if quote != 0 {
+		return BPFunction{}, fmt.Errorf("invalid business process function %q", text)
+	}
+	if strings.TrimSpace(inner) != "" {
+		flush()
+	}
+	return f, nil
+}
+
+func (f BPFunction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+func (f *BPFunction) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	parsed, err := ParseBPFunction(text)
+	if err != nil {
+		return err
+	}
+	*f = parsed
+	return nil
+}
+
+type BPNode struct {
+	ID                 string     `json:"id"`
+	Label              string     `json:"label"`
+	Function           BPFunction `json:"function"`
+	Depends            []string   `json:"depends,omitempty"`
+	Host               string     `json:"host,omitempty"`
+	Service            string     `json:"service,omitempty"`
+	Template           string     `json:"template,omitempty"`
+	CreateObj          int        `json:"create_obj,omitempty"`
+	NotificationPeriod string     `json:"notification_period,omitempty"`
+	EventHandler       string     `json:"event_handler,omitempty"`
+	Contactgroups      []string   `json:"contactgroups,omitempty"`
+	Contacts           []string   `json:"contacts,omitempty"`
+}
+
+// BP is a business process. Its first node is the top node, the state of
+// which is the state of the business process.
+type BP struct {
+	NR        int      `json:"nr,omitempty"`
+	Name      string   `json:"name"`
+	Template  string   `json:"template,omitempty"`
+	RankDir   string   `json:"rankDir,omitempty"`
+	StateType string   `json:"state_type,omitempty"`
+	Nodes     []BPNode `json:"nodes"`
+}
+
+func (t Thruk) bpURL(nr int) string {
+	URL := "/" + t.SiteName + "/thruk/r/thruk/bp"
+	if nr > 0 {
+		URL += "/" + strconv.Itoa(nr)
+	}
+	return URL
+}
+
+func (t Thruk) ListBPs() ([]BP, error) {
+	var bps []BP
+	err := t.getJSON(t.bpURL(0), &bps)
+	return bps, err
+}
+
+func (t Thruk) GetBP(nr int) (BP, error) {
+	if nr <= 0 {
+		return BP{}, ErrorInvalidInput
+	}
+	var bps []BP
+	if err := t.getJSON(t.bpURL(nr), &bps); err != nil {
+		return BP{}, err
+	}
+	if len(bps) == 0 {
+		return BP{}, ErrorObjectNotFound
+	}
+	return bps[0], nil
+}
+
+// CreateBP creates a business process and returns its number.
+func (t Thruk) CreateBP(bp BP) (int, error) {
+	if bp.Name == "" {
+		return 0, ErrorInvalidInput
+	}
+	if err := bp.Validate(); err != nil {
+		return 0, err
+	}
+	bp.NR = 0
+	resp, err := t.sendJSON("POST", t.bpURL(0), bp)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	created := struct {
+		NR int `json:"nr"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return 0, err
+	}
+	if created.NR == 0 {
+		return 0, errors.New("business process not created")
+	}
+	return created.NR, nil
+}
+
+// UpdateBP replaces the business process bp.NR.
+func (t Thruk) UpdateBP(bp BP) error {
+	if bp.NR <= 0 {
+		return ErrorNeedID
+	}
+	if err := bp.Validate(); err != nil {
+		return err
+	}
+	resp, err := t.sendJSON("POST", t.bpURL(bp.NR), bp)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (t Thruk) DeleteBP(nr int) error {
+	if nr <= 0 {
+		return ErrorNeedID
+	}
+	resp, err := t.sendJSON("DELETE", t.bpURL(nr), nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Validate checks that the business process has nodes, that all
+// dependencies exist, that they do not form a cycle and that the functions
+// the evaluator knows have valid arguments. Functions the evaluator does
+// not support, like equals or custom, are accepted as Thruk knows them.
+func (bp BP) Validate() error {
+	if len(bp.Nodes) == 0 {
+		return errors.New("business process has no nodes")
+	}
+	_, err := bp.evaluate(nil, true)
+	return err
+}
+
+// BPStates holds the states business processes are evaluated against, keyed
+// by host and service. Host states are keyed with an empty Service.
+type BPStates map[ServiceRef]int
+
+// Evaluate computes the state of every node from the given host and service
+// states and returns them by node ID. Objects without a state are UNKNOWN,
+// down and unreachable hosts count as CRITICAL. Functions the evaluator
+// does not support return an error.
+func (bp BP) Evaluate(states BPStates) (map[string]int, error) {
+	return bp.evaluate(states, false)
+}
+
+func (bp BP) evaluate(states BPStates, validating bool) (map[string]int, error) {
+	nodes := map[string]BPNode{}
+	for _, node := range bp.Nodes {
+		if node.ID == "" {
+			return nil, errors.New("business process node without id")
+		}
+		if _, ok := nodes[node.ID]; ok {
+			return nil, fmt.Errorf("duplicate business process node %s", node.ID)
+		}
+		nodes[node.ID] = node
+	}
+	e := bpEvaluation{nodes: nodes, states: states, validating: validating, results: map[string]int{}, visiting: map[string]bool{}}
+	for _, node := range bp.Nodes {
+		if _, err := e.state(node.ID); err != nil {
+			return nil, err
+		}
+	}
+	return e.results, nil
+}
+
+// State returns the state of the business process, the state of its top
+// node.
+func (bp BP) State(states BPStates) (int, error) {
+	results, err := bp.Evaluate(states)
+	if err != nil {
+		return StateUnknown, err
+	}
+	return results[bp.Nodes[0].ID], nil
+}
+
+type bpEvaluation struct {
+	nodes  map[string]BPNode
+	states BPStates
+	// validating accepts unsupported functions, their state is UNKNOWN
+	validating bool
+	results    map[string]int
+	visiting   map[string]bool
+}
+
+// severity orders states from best to worst: OK, WARNING, UNKNOWN,
+// CRITICAL.
+func severity(state int) int {
+	switch state {
+	case StateOK:
+		return 0
+	case StateWarning:
+		return 1
+	case StateCritical:
+		return 3
+	}
+	return 2
+}
+
+var bpStateNames = map[string]int{"OK": StateOK, "UP": StateOK, "WARNING": StateWarning, "CRITICAL": StateCritical, "DOWN": StateCritical, "UNREACHABLE": StateCritical, "UNKNOWN": StateUnknown}
+
+func (e *bpEvaluation) state(id string) (int, error) {
+	if state, ok := e.results[id]; ok {
+		return state, nil
+	}
+	node, ok := e.nodes[id]
+	if !ok {
+		return StateUnknown, fmt.Errorf("business process node %s not found", id)
+	}
+	if e.visiting[id] {
+		return StateUnknown, fmt.Errorf("business process node %s depends on itself", id)
+	}
+	e.visiting[id] = true
+	defer delete(e.visiting, id)
+
+	var children []int
+	for _, dependency := range node.Depends {
+		state, err := e.state(dependency)
+		if err != nil {
+			return StateUnknown, err
+		}
+		children = append(children, state)
+	}
+	state, err := e.apply(node, children)
+	if err != nil {
+		return StateUnknown, fmt.Errorf("business process node %s: %v", id, err)
+	}
+	e.results[id] = state
+	return state, nil
+}
+
+func (e *bpEvaluation) apply(node BPNode, children []int) (int, error) {
+	f := node.Function
+	switch f.Name {
+	case "status":
+		host, service := node.Host, node.Service
+		if len(f.Args) >= 2 {
+			host, service = f.Args[0], f.Args[1]
+		}
+		if host == "" {
+			return StateUnknown, errors.New("status needs a host")
+		}
+		state, ok := e.states[ServiceRef{Host: host, Service: service}]
+		if !ok {
+			return StateUnknown, nil
+		}
+		if service == "" && state != StateOK {
+			return StateCritical, nil
+		}
+		return state, nil
+	case "fixed":
+		if len(f.Args) == 0 {
+			return StateUnknown, errors.New("fixed needs a state")
+		}
+		state, ok := bpStateNames[strings.ToUpper(f.Args[0])]
+		if !ok {
+			return StateUnknown, fmt.Errorf("unknown state %s", f.Args[0])
+		}
+		return state, nil
+	case "worst", "best":
+		if len(children) == 0 {
+			return StateUnknown, nil
+		}
+		result := children[0]
+		for _, state := range children[1:] {
+			if (f.Name == "worst") == (severity(state) > severity(result)) {
+				result = state
+			}
+		}
+		return result, nil
+	case "at_least", "not_more":
+		if len(f.Args) < 2 {
+			return StateUnknown, fmt.Errorf("%s needs a warning and a critical threshold", f.Name)
+		}
+		warning, err := bpThreshold(f.Args[0], len(children))
+		if err != nil {
+			return StateUnknown, err
+		}
+		critical, err := bpThreshold(f.Args[1], len(children))
+		if err != nil {
+			return StateUnknown, err
+		}
+		var good float64
+		for _, state := range children {
+			if state == StateOK {
+				good++
+			}
+		}
+		if f.Name == "at_least" {
+			switch {
+			case good >= warning:
+				return StateOK, nil
+			case good >= critical:
+				return StateWarning, nil
+			}
+			return StateCritical, nil
+		}
+		bad := float64(len(children)) - good
+		switch {
+		case bad > critical:
+			return StateCritical, nil
+		case bad > warning:
+			return StateWarning, nil
+		}
+		return StateOK, nil
+	}
+	if e.validating {
+		return StateUnknown, nil
+	}
+	return StateUnknown, fmt.Errorf("unsupported function %s", f.Name)
+}
+
+// bpThreshold returns a threshold as a number of nodes, resolving
+// percentages against total.
+func bpThreshold(value string, total int) (float64, error) {
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil {
+			return 0, err
+		}
+		return percent * float64(total) / 100, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
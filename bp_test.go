@@ -0,0 +1,175 @@
+package thruk
+
+import (
+	"encoding/json"
+	"gotest.tools/assert"
+	"testing"
+)
+
+var webshop = BP{
+	Name: "webshop",
+	Nodes: []BPNode{
+		{ID: "node1", Label: "webshop", Function: BPWorst(), Depends: []string{"node2", "node3"}},
+		{ID: "node2", Label: "frontends", Function: BPAtLeast("2", "1"), Depends: []string{"node4", "node5", "node6"}},
+		{ID: "node3", Label: "database", Function: BPStatus("db01", "mysql")},
+		{ID: "node4", Label: "web01", Function: BPStatus("web01", "HTTP")},
+		{ID: "node5", Label: "web02", Function: BPStatus("web02", "HTTP")},
+		{ID: "node6", Label: "web03", Function: BPStatus("web03", "")},
+	},
+}
+
+func Test_BPFunction(t *testing.T) {
+	t.Run("Functions are parsed from Thruk notation", func(t *testing.T) {
+		f, err := ParseBPFunction(`status('localhost', 'Disk C:\'s space', '=')`)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, f, BPFunction{Name: "status", Args: []string{"localhost", "Disk C:'s space", "="}})
+
+		f, err = ParseBPFunction("at_least(2, 50%)")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, f, BPAtLeast("2", "50%"))
+
+		f, err = ParseBPFunction("worst()")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, f, BPWorst())
+	})
+	t.Run("Functions round trip through JSON", func(t *testing.T) {
+		encoded, err := json.Marshal(BPStatus("localhost", "it's"))
+		assert.NilError(t, err)
+		assert.Equal(t, string(encoded), `"status('localhost', 'it\\'s', '=')"`)
+
+		var f BPFunction
+		assert.NilError(t, json.Unmarshal(encoded, &f))
+		assert.DeepEqual(t, f, BPStatus("localhost", "it's"))
+	})
+	t.Run("Invalid functions return error", func(t *testing.T) {
+		for _, text := range []string{"worst", "(1)", "status('a"} {
+			_, err := ParseBPFunction(text)
+			assert.Assert(t, err != nil, text)
+		}
+	})
+}
+
+func Test_BP_Evaluate(t *testing.T) {
+	allOK := BPStates{
+		{Host: "db01", Service: "mysql"}: StateOK,
+		{Host: "web01", Service: "HTTP"}: StateOK,
+		{Host: "web02", Service: "HTTP"}: StateOK,
+		{Host: "web03"}:                  StateOK,
+	}
+	with := func(ref ServiceRef, state int) BPStates {
+		states := BPStates{}
+		for k, v := range allOK {
+			states[k] = v
+		}
+		states[ref] = state
+		return states
+	}
+
+	t.Run("All services OK", func(t *testing.T) {
+		state, err := webshop.State(allOK)
+		assert.NilError(t, err)
+		assert.Equal(t, state, StateOK)
+	})
+	t.Run("At least tolerates a failing frontend", func(t *testing.T) {
+		results, err := webshop.Evaluate(with(ServiceRef{Host: "web01", Service: "HTTP"}, StateCritical))
+		assert.NilError(t, err)
+		assert.Equal(t, results["node4"], StateCritical)
+		assert.Equal(t, results["node2"], StateOK)
+		assert.Equal(t, results["node1"], StateOK)
+	})
+	t.Run("Down hosts are critical", func(t *testing.T) {
+		results, err := webshop.Evaluate(with(ServiceRef{Host: "web03"}, 1))
+		assert.NilError(t, err)
+		assert.Equal(t, results["node6"], StateCritical)
+	})
+	t.Run("Worst propagates the database state", func(t *testing.T) {
+		state, err := webshop.State(with(ServiceRef{Host: "db01", Service: "mysql"}, StateWarning))
+		assert.NilError(t, err)
+		assert.Equal(t, state, StateWarning)
+	})
+	t.Run("Missing states are unknown", func(t *testing.T) {
+		state, err := webshop.State(BPStates{})
+		assert.NilError(t, err)
+		assert.Equal(t, state, StateCritical)
+
+		results, _ := webshop.Evaluate(BPStates{})
+		assert.Equal(t, results["node3"], StateUnknown)
+	})
+	t.Run("Worst ranks critical above unknown and best picks the best", func(t *testing.T) {
+		bp := BP{Nodes: []BPNode{
+			{ID: "worst", Function: BPWorst(), Depends: []string{"unknown", "critical"}},
+			{ID: "best", Function: BPBest(), Depends: []string{"unknown", "warning"}},
+			{ID: "unknown", Function: BPFixed("UNKNOWN")},
+			{ID: "critical", Function: BPFixed("CRITICAL")},
+			{ID: "warning", Function: BPFixed("WARNING")},
+		}}
+		results, err := bp.Evaluate(nil)
+		assert.NilError(t, err)
+		assert.Equal(t, results["worst"], StateCritical)
+		assert.Equal(t, results["best"], StateWarning)
+	})
+	t.Run("Not more counts failing children with percentages", func(t *testing.T) {
+		bp := BP{Nodes: []BPNode{
+			{ID: "top", Function: BPNotMore("0", "50%"), Depends: []string{"a", "b", "c", "d"}},
+			{ID: "a", Function: BPFixed("CRITICAL")},
+			{ID: "b", Function: BPFixed("CRITICAL")},
+			{ID: "c", Function: BPFixed("OK")},
+			{ID: "d", Function: BPFixed("OK")},
+		}}
+		state, err := bp.State(nil)
+		assert.NilError(t, err)
+		assert.Equal(t, state, StateWarning)
+	})
+	t.Run("Cycles and missing nodes return error", func(t *testing.T) {
+		cycle := BP{Nodes: []BPNode{
+			{ID: "a", Function: BPWorst(), Depends: []string{"b"}},
+			{ID: "b", Function: BPWorst(), Depends: []string{"a"}},
+		}}
+		assert.Error(t, cycle.Validate(), "business process node a depends on itself")
+
+		missing := BP{Nodes: []BPNode{{ID: "a", Function: BPWorst(), Depends: []string{"b"}}}}
+		assert.Error(t, missing.Validate(), "business process node b not found")
+	})
+	t.Run("Validate accepts functions the evaluator does not support", func(t *testing.T) {
+		var bp BP
+		assert.NilError(t, json.Unmarshal([]byte(`{"name":"shop","nodes":[
+			{"id":"node1","label":"shop","function":"equals('2')","depends":["node2","node3"]},
+			{"id":"node2","label":"custom","function":"custom('check', '1')"},
+			{"id":"node3","label":"filter","function":"statusfilter('web', 'hostgroup', '~')"}]}`), &bp))
+		assert.NilError(t, bp.Validate())
+
+		_, err := bp.State(nil)
+		assert.Error(t, err, "business process node node2: unsupported function custom")
+	})
+	t.Run("Validate checks the arguments of supported functions", func(t *testing.T) {
+		bp := BP{Nodes: []BPNode{
+			{ID: "a", Function: BPFunction{Name: "unknown"}, Depends: []string{"b"}},
+			{ID: "b", Function: BPAtLeast("x", "1")},
+		}}
+		assert.Assert(t, bp.Validate() != nil)
+	})
+}
+
+func Test_thruk_client_BP(t *testing.T) {
+	t.Run("Create, get, update and delete a business process", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		nr, err := thruk.CreateBP(webshop)
+		assert.NilError(t, err)
+
+		bp, err := thruk.GetBP(nr)
+		assert.NilError(t, err)
+		assert.Equal(t, bp.Name, "webshop")
+		assert.Equal(t, len(bp.Nodes), len(webshop.Nodes))
+
+		bp.Name = "webshop2"
+		assert.NilError(t, thruk.UpdateBP(bp))
+		bp, err = thruk.GetBP(nr)
+		assert.NilError(t, err)
+		assert.Equal(t, bp.Name, "webshop2")
+
+		assert.NilError(t, thruk.DeleteBP(nr))
+		_, err = thruk.GetBP(nr)
+		assert.Assert(t, err != nil)
+	})
+}
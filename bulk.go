@@ -0,0 +1,238 @@
+package thruk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type BulkAction int
+
+const (
+	BulkCreate BulkAction = iota
+	BulkUpdate
+	BulkDelete
+)
+
+func (a BulkAction) String() string {
+	switch a {
+	case BulkCreate:
+		return "create"
+	case BulkUpdate:
+		return "update"
+	case BulkDelete:
+		return "delete"
+	}
+	return fmt.Sprintf("BulkAction(%d)", int(a))
+}
+
+// BulkOperation is a single change applied by BulkApply. Object can be any
+// of the typed objects (Host, Service, Command, ConfigObject, ...). Updates
+// take the ID from the object's :ID, deletes only need ID.
+type BulkOperation struct {
+	Action BulkAction
+	Object interface{}
+	ID     string
+}
+
+type BulkOptions struct {
+	// Concurrency is the number of requests in flight, 4 when zero.
+	Concurrency int
+	// StopOnError skips all operations not yet started after the first
+	// failure. Operations already in flight are completed.
+	StopOnError bool
+	// SaveAndReload saves and reloads the configuration once all
+	// operations succeeded.
+	SaveAndReload bool
+	// Progress is called after every operation, never concurrently.
+	Progress func(done, total int, result BulkResult)
+}
+
+type BulkResult struct {
+	Index     int
+	Operation BulkOperation
+	// ID of the created, updated or deleted object.
+	ID      string
+	Err     error
+	Skipped bool
+	// Indeterminate is set when ctx was done while the request was in
+	// flight, Thruk may or may not have staged the change.
+	Indeterminate bool
+}
+
+type BulkReport struct {
+	Results       []BulkResult
+	Succeeded     int
+	Failed        int
+	Skipped       int
+	Indeterminate int
+}
+
+// BulkError is returned by BulkApply when at least one operation failed.
+type BulkError struct {
+	Failed []BulkResult
+}
+
+func (e *BulkError) Error() string {
+	messages := make([]string, 0, len(e.Failed))
+	for _, result := range e.Failed {
+		messages = append(messages, fmt.Sprintf("#%d %s %s: %s", result.Index, result.Operation.Action, result.ID, result.Err))
+	}
+	return fmt.Sprintf("%d bulk operations failed: %s", len(e.Failed), strings.Join(messages, "; "))
+}
+
+var ErrorUnknownBulkAction = errors.New("[ERROR] unknown bulk action")
+
+// BulkApply runs creates, updates and deletes with bounded concurrency and
+// reports the outcome of every operation. When opts.SaveAndReload is set
+// and nothing failed, the changes are saved and reloaded once at the end;
+// otherwise they stay staged so the caller can inspect or discard them.
+// Only ctx cancels requests in flight.
+func (t Thruk) BulkApply(ctx context.Context, ops []BulkOperation, opts BulkOptions) (BulkReport, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	report := BulkReport{Results: make([]BulkResult, len(ops))}
+	indexes := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	stopped := false
+
+	finish := func(result BulkResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		report.Results[result.Index] = result
+		done++
+		if result.Err != nil && !result.Indeterminate && opts.StopOnError {
+			stopped = true
+		}
+		if opts.Progress != nil {
+			opts.Progress(done, len(ops), result)
+		}
+	}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				mu.Lock()
+				skip := stopped
+				mu.Unlock()
+				if skip {
+					finish(BulkResult{Index: index, Operation: ops[index], ID: ops[index].ID, Skipped: true})
+					continue
+				}
+				finish(t.applyBulkOperation(ctx, index, ops[index]))
+			}
+		}()
+	}
+
+	for index := range ops {
+		mu.Lock()
+		skip := stopped || ctx.Err() != nil
+		mu.Unlock()
+		if skip {
+			finish(BulkResult{Index: index, Operation: ops[index], ID: ops[index].ID, Skipped: true})
+			continue
+		}
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	var failed []BulkResult
+	for _, result := range report.Results {
+		switch {
+		case result.Skipped:
+			report.Skipped++
+		case result.Indeterminate:
+			report.Indeterminate++
+		case result.Err != nil:
+			report.Failed++
+			failed = append(failed, result)
+		default:
+			report.Succeeded++
+		}
+	}
+	if len(failed) > 0 {
+		return report, &BulkError{Failed: failed}
+	}
+	if report.Skipped > 0 || report.Indeterminate > 0 {
+		return report, ctx.Err()
+	}
+	if opts.SaveAndReload {
+		return report, t.SaveAndReloadConfigs()
+	}
+	return report, nil
+}
+
+func (t Thruk) applyBulkOperation(ctx context.Context, index int, op BulkOperation) BulkResult {
+	result := BulkResult{Index: index, Operation: op, ID: op.ID}
+	if err := ctx.Err(); err != nil {
+		result.Skipped = true
+		return result
+	}
+
+	switch op.Action {
+	case BulkCreate:
+		attributes, err := objectAttributes(op.Object)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		file, _ := attributes[":FILE"].(string)
+		objectType, _ := attributes[":TYPE"].(string)
+		if file == "" || objectType == "" {
+			result.Err = ErrorNeedFileAndType
+			return result
+		}
+		result.ID, result.Err = t.createConfigObjectContext(ctx, op.Object)
+	case BulkUpdate:
+		attributes, err := objectAttributes(op.Object)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		id, _ := attributes[":ID"].(string)
+		if id == "" {
+			result.Err = ErrorNeedID
+			return result
+		}
+		result.ID = id
+		result.Err = t.replaceConfigObjectContext(ctx, id, op.Object)
+	case BulkDelete:
+		if op.ID == "" {
+			result.Err = ErrorNeedID
+			return result
+		}
+		result.Err = t.deleteConfigObjectContext(ctx, op.ID)
+	default:
+		result.Err = ErrorUnknownBulkAction
+		return result
+	}
+	result.Indeterminate = result.Err != nil && ctx.Err() != nil
+	return result
+}
+
+// objectAttributes returns the JSON attributes of any typed object.
+func objectAttributes(object interface{}) (map[string]interface{}, error) {
+	if object == nil {
+		return nil, ErrorInvalidInput
+	}
+	bodyBytes, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	attributes := map[string]interface{}{}
+	err = json.Unmarshal(bodyBytes, &attributes)
+	if err != nil {
+		return nil, ErrorInvalidInput
+	}
+	return attributes, nil
+}
@@ -0,0 +1,189 @@
+package thruk
+
+import (
+	"context"
+	"fmt"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_thruk_client_BulkApply(t *testing.T) {
+	t.Run("Bulk apply creates all objects and reports their IDs", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		var ops []BulkOperation
+		for i := 0; i < 20; i++ {
+			ops = append(ops, BulkOperation{Action: BulkCreate, Object: Host{
+				FILE:     "bulk.cfg",
+				TYPE:     "host",
+				HostName: fmt.Sprintf("bulk-%02d", i),
+				Address:  "127.0.0.1",
+			}})
+		}
+		progress := 0
+		report, err := thruk.BulkApply(context.Background(), ops, BulkOptions{
+			Concurrency: 5,
+			Progress: func(done, total int, result BulkResult) {
+				progress = done
+				assert.Equal(t, total, 20)
+			},
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, report.Succeeded, 20)
+		assert.Equal(t, progress, 20)
+		for _, result := range report.Results {
+			host, err := thruk.GetHost(result.ID)
+			assert.NilError(t, err)
+			assert.Equal(t, host.HostName, fmt.Sprintf("bulk-%02d", result.Index))
+		}
+	})
+	t.Run("Bulk apply updates and deletes existing objects", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		updateID, err := thruk.CreateCommand(Command{FILE: "bulk.cfg", TYPE: "command", CommandName: "bulk_update", CommandLine: "true"})
+		assert.NilError(t, err)
+		deleteID, err := thruk.CreateCommand(Command{FILE: "bulk.cfg", TYPE: "command", CommandName: "bulk_delete", CommandLine: "true"})
+		assert.NilError(t, err)
+		command, err := thruk.GetCommand(updateID)
+		assert.NilError(t, err)
+		command.CommandLine = "false"
+
+		report, err := thruk.BulkApply(context.Background(), []BulkOperation{
+			{Action: BulkUpdate, Object: command},
+			{Action: BulkDelete, ID: deleteID},
+		}, BulkOptions{})
+		assert.NilError(t, err)
+		assert.Equal(t, report.Succeeded, 2)
+
+		updated, err := thruk.GetCommand(updateID)
+		assert.NilError(t, err)
+		assert.Equal(t, updated.CommandLine, "false")
+		_, err = thruk.GetCommand(deleteID)
+		assert.Error(t, err, "[ERROR] Object not found")
+	})
+	t.Run("Bulk apply collects errors per operation", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		report, err := thruk.BulkApply(context.Background(), []BulkOperation{
+			{Action: BulkCreate, Object: ConfigObject{}},
+			{Action: BulkUpdate, Object: Host{}},
+			{Action: BulkCreate, Object: Host{FILE: "bulk.cfg", TYPE: "host", HostName: "bulk-ok"}},
+		}, BulkOptions{})
+		assert.ErrorType(t, err, &BulkError{})
+		assert.Equal(t, report.Failed, 2)
+		assert.Equal(t, report.Succeeded, 1)
+		assert.Equal(t, report.Results[0].Err, ErrorNeedFileAndType)
+		assert.Equal(t, report.Results[1].Err, ErrorNeedID)
+	})
+	t.Run("Bulk apply skips remaining operations when stopping on first error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		report, err := thruk.BulkApply(context.Background(), []BulkOperation{
+			{Action: BulkDelete},
+			{Action: BulkCreate, Object: Host{FILE: "bulk.cfg", TYPE: "host", HostName: "bulk-skipped"}},
+		}, BulkOptions{Concurrency: 1, StopOnError: true})
+		assert.ErrorType(t, err, &BulkError{})
+		assert.Equal(t, report.Failed, 1)
+		assert.Equal(t, report.Skipped, 1)
+	})
+	t.Run("Bulk apply saves and reloads once all operations succeeded", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		report, err := thruk.BulkApply(context.Background(), []BulkOperation{
+			{Action: BulkCreate, Object: Host{FILE: "bulk.cfg", TYPE: "host", HostName: "bulk-saved", Address: "127.0.0.1", Use: []string{"generic-host"}}},
+		}, BulkOptions{SaveAndReload: true})
+		assert.NilError(t, err)
+
+		err = thruk.DiscardConfigs()
+		assert.NilError(t, err)
+		host, err := thruk.GetHost(report.Results[0].ID)
+		assert.NilError(t, err)
+		assert.Equal(t, host.HostName, "bulk-saved")
+	})
+}
+
+func Test_BulkApply_InFlight(t *testing.T) {
+	ops := []BulkOperation{
+		{Action: BulkCreate, Object: Host{FILE: "bulk.cfg", TYPE: "host", HostName: "slow"}},
+		{Action: BulkDelete, ID: "bad"},
+		{Action: BulkCreate, Object: Host{FILE: "bulk.cfg", TYPE: "host", HostName: "late"}},
+	}
+
+	t.Run("Stopping on error lets operations in flight finish", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "DELETE" {
+				http.Error(w, "no such object", http.StatusInternalServerError)
+				return
+			}
+			<-release
+			w.Write([]byte(`{"objects":[{":ID":"slow1"}]}`))
+		}))
+		defer server.Close()
+		thruk := NewThruk(server.URL, "site", "user", "pass", false)
+
+		report, err := thruk.BulkApply(context.Background(), ops, BulkOptions{
+			Concurrency: 2,
+			StopOnError: true,
+			Progress: func(done, total int, result BulkResult) {
+				if result.Err != nil {
+					close(release)
+				}
+			},
+		})
+		assert.ErrorType(t, err, &BulkError{})
+		assert.Equal(t, report.Succeeded, 1)
+		assert.Equal(t, report.Failed, 1)
+		assert.Equal(t, report.Skipped, 1)
+		assert.Equal(t, report.Results[0].ID, "slow1")
+		assert.NilError(t, report.Results[0].Err)
+		assert.Assert(t, report.Results[2].Skipped)
+	})
+
+	t.Run("Operations cut off by the caller are indeterminate", func(t *testing.T) {
+		received := make(chan struct{})
+		stop := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(received)
+			<-stop
+		}))
+		defer server.Close()
+		defer close(stop)
+		thruk := NewThruk(server.URL, "site", "user", "pass", false)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-received
+			cancel()
+		}()
+
+		report, err := thruk.BulkApply(ctx, ops[:1], BulkOptions{StopOnError: true})
+		assert.Equal(t, err, context.Canceled)
+		assert.Equal(t, report.Indeterminate, 1)
+		assert.Equal(t, report.Failed, 0)
+		assert.Assert(t, report.Results[0].Indeterminate)
+	})
+}
+
+func Test_BulkApply_Validation(t *testing.T) {
+	t.Run("Objects without FILE or TYPE are rejected before sending", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Write([]byte(`{"objects":[{":ID":"new1"}]}`))
+		}))
+		defer server.Close()
+		thruk := NewThruk(server.URL, "site", "user", "pass", false)
+
+		report, err := thruk.BulkApply(context.Background(), []BulkOperation{
+			{Action: BulkCreate, Object: map[string]interface{}{":TYPE": "host", "host_name": "nofile"}},
+			{Action: BulkCreate, Object: map[string]interface{}{":FILE": "bulk.cfg", "host_name": "notype"}},
+		}, BulkOptions{})
+		assert.ErrorType(t, err, &BulkError{})
+		assert.Equal(t, report.Failed, 2)
+		assert.Equal(t, report.Results[0].Err, ErrorNeedFileAndType)
+		assert.Equal(t, report.Results[1].Err, ErrorNeedFileAndType)
+		assert.Equal(t, requests, 0)
+	})
+}
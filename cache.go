@@ -0,0 +1,200 @@
+package thruk
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrorCacheDisabled = errors.New("[ERROR] config cache is not enabled")
+
+type CacheOptions struct {
+	// TTL is how long objects are cached, a minute when not set.
+	TTL time.Duration
+	// TypeTTL overrides TTL per object type, e.g. {"command": time.Hour}.
+	TypeTTL map[string]time.Duration
+	// MaxObjects limits the number of cached objects, the least recently
+	// used are evicted first. Unlimited when not set.
+	MaxObjects int
+}
+
+type CacheStats struct {
+	Hits      int
+	Misses    int
+	Evictions int
+	Size      int
+}
+
+// WithConfigCache caches config objects read by ID, like GetHost or
+// GetService, and every object returned by config listings. Objects
+// changed or deleted through the client are invalidated right away, a
+// discard clears the cache. Changes made elsewhere show up after the TTL.
+func WithConfigCache(opts CacheOptions) Option {
+	return func(t *Thruk) {
+		if opts.TTL <= 0 {
+			opts.TTL = time.Minute
+		}
+		t.cache = &configCache{opts: opts, entries: map[string]*list.Element{}, lru: list.New()}
+	}
+}
+
+type cacheEntry struct {
+	id         string
+	objectType string
+	raw        json.RawMessage
+	expires    time.Time
+}
+
+type configCache struct {
+	sync.Mutex
+	opts    CacheOptions
+	entries map[string]*list.Element
+	lru     *list.List
+	stats   CacheStats
+}
+
+func (c *configCache) get(id string) (cacheEntry, bool) {
+	if c == nil {
+		return cacheEntry{}, false
+	}
+	c.Lock()
+	defer c.Unlock()
+	element, ok := c.entries[id]
+	if ok && time.Now().Before(element.Value.(cacheEntry).expires) {
+		c.lru.MoveToFront(element)
+		c.stats.Hits++
+		return element.Value.(cacheEntry), true
+	}
+	if ok {
+		c.remove(element)
+	}
+	c.stats.Misses++
+	return cacheEntry{}, false
+}
+
+func (c *configCache) put(objects []json.RawMessage) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	now := time.Now()
+	for _, raw := range objects {
+		var header struct {
+			ID   string `json:":ID"`
+			TYPE string `json:":TYPE"`
+		}
+		if json.Unmarshal(raw, &header) != nil || header.ID == "" {
+			continue
+		}
+		ttl, ok := c.opts.TypeTTL[header.TYPE]
+		if !ok {
+			ttl = c.opts.TTL
+		}
+		entry := cacheEntry{id: header.ID, objectType: header.TYPE, raw: raw, expires: now.Add(ttl)}
+		if element, ok := c.entries[header.ID]; ok {
+			element.Value = entry
+			c.lru.MoveToFront(element)
+			continue
+		}
+		c.entries[header.ID] = c.lru.PushFront(entry)
+		if c.opts.MaxObjects > 0 && c.lru.Len() > c.opts.MaxObjects {
+			c.remove(c.lru.Back())
+			c.stats.Evictions++
+		}
+	}
+}
+
+func (c *configCache) remove(element *list.Element) {
+	delete(c.entries, element.Value.(cacheEntry).id)
+	c.lru.Remove(element)
+}
+
+// invalidate drops what a request may have changed: the object of a
+// request to config/objects/<id> or everything on a discard.
+func (c *configCache) invalidate(method, URL string) {
+	if c == nil || method == "GET" {
+		return
+	}
+	path := strings.SplitN(URL, "?", 2)[0]
+	c.Lock()
+	defer c.Unlock()
+	if strings.HasSuffix(path, "/thruk/r/config/discard") {
+		c.entries = map[string]*list.Element{}
+		c.lru.Init()
+		return
+	}
+	const objects = "/thruk/r/config/objects/"
+	if i := strings.Index(path, objects); i >= 0 {
+		if element, ok := c.entries[path[i+len(objects):]]; ok {
+			c.remove(element)
+		}
+	}
+}
+
+// idLookup reports whether filter asks for a single object by ID,
+// optionally restricted to a type.
+func idLookup(filter url.Values) (id, objectType string, ok bool) {
+	for key := range filter {
+		if key != ":ID" && key != ":TYPE" {
+			return "", "", false
+		}
+	}
+	if len(filter[":ID"]) != 1 || len(filter[":TYPE"]) > 1 {
+		return "", "", false
+	}
+	return filter.Get(":ID"), filter.Get(":TYPE"), true
+}
+
+// findConfigObjects decodes the config objects matching filter into v,
+// which must point to a slice.
+func (t Thruk) findConfigObjects(filter url.Values, v interface{}) error {
+	if id, objectType, ok := idLookup(filter); ok && t.cache != nil {
+		if entry, hit := t.cache.get(id); hit {
+			var objects []json.RawMessage
+			if objectType == "" || objectType == entry.objectType {
+				objects = append(objects, entry.raw)
+			}
+			return decodeRawList(objects, v)
+		}
+	}
+	var objects []json.RawMessage
+	if err := t.listAll(context.Background(), "config/objects", filter, &objects); err != nil {
+		return err
+	}
+	// objects reduced to some columns must not be served as whole objects
+	if _, ok := filter["columns"]; !ok {
+		t.cache.put(objects)
+	}
+	return decodeRawList(objects, v)
+}
+
+// WarmConfigCache loads all config objects into the cache and returns
+// their number.
+func (t Thruk) WarmConfigCache() (int, error) {
+	if t.cache == nil {
+		return 0, ErrorCacheDisabled
+	}
+	var objects []json.RawMessage
+	if err := t.listAll(context.Background(), "config/objects", nil, &objects); err != nil {
+		return 0, err
+	}
+	t.cache.put(objects)
+	return len(objects), nil
+}
+
+func (t Thruk) CacheStats() CacheStats {
+	if t.cache == nil {
+		return CacheStats{}
+	}
+	t.cache.Lock()
+	defer t.cache.Unlock()
+	stats := t.cache.stats
+	stats.Size = t.cache.lru.Len()
+	return stats
+}
@@ -0,0 +1,151 @@
+package thruk
+
+import (
+	"encoding/json"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConfig lists objects and applies patches to them, other changes are
+// only acknowledged.
+type fakeConfig struct {
+	sync.Mutex
+	objects  []map[string]interface{}
+	requests int
+}
+
+func (f *fakeConfig) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	f.requests++
+	if r.Method == "PATCH" {
+		var changes map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&changes)
+		for _, object := range f.objects {
+			if strings.HasSuffix(r.URL.Path, "/config/objects/"+object[":ID"].(string)) {
+				for key, value := range changes {
+					object[key] = value
+				}
+			}
+		}
+	}
+	if r.Method != "GET" {
+		json.NewEncoder(w).Encode(map[string]interface{}{"message": "ok"})
+		return
+	}
+	matching := []map[string]interface{}{}
+	for _, object := range f.objects {
+		if id := r.URL.Query().Get(":ID"); id != "" && object[":ID"] != id {
+			continue
+		}
+		if objectType := r.URL.Query().Get(":TYPE"); objectType != "" && object[":TYPE"] != objectType {
+			continue
+		}
+		matching = append(matching, object)
+	}
+	if r.URL.Query().Get("offset") != "0" {
+		matching = nil
+	}
+	json.NewEncoder(w).Encode(matching)
+}
+
+func startFakeConfigServer(opts CacheOptions) (*Thruk, *fakeConfig, func()) {
+	config := &fakeConfig{objects: []map[string]interface{}{
+		{":ID": "h1", ":TYPE": "host", "host_name": "web01"},
+		{":ID": "h2", ":TYPE": "host", "host_name": "web02"},
+		{":ID": "c1", ":TYPE": "command", "command_name": "check_ping"},
+	}}
+	server := httptest.NewServer(config)
+	return NewThruk(server.URL, "site", "user", "pass", false, WithConfigCache(opts)), config, server.Close
+}
+
+func Test_ConfigCache(t *testing.T) {
+	t.Run("Reads by ID are served from the cache", func(t *testing.T) {
+		thruk, config, closeServer := startFakeConfigServer(CacheOptions{})
+		defer closeServer()
+
+		for i := 0; i < 3; i++ {
+			host, err := thruk.GetHost("h1")
+			assert.NilError(t, err)
+			assert.Equal(t, host.HostName, "web01")
+		}
+		assert.Equal(t, config.requests, 1)
+		assert.DeepEqual(t, thruk.CacheStats(), CacheStats{Hits: 2, Misses: 1, Size: 1})
+	})
+	t.Run("Cached objects of another type are not returned", func(t *testing.T) {
+		thruk, _, closeServer := startFakeConfigServer(CacheOptions{})
+		defer closeServer()
+
+		_, err := thruk.GetHost("h1")
+		assert.NilError(t, err)
+		_, err = thruk.GetCommand("h1")
+		assert.Equal(t, err, ErrorObjectNotFound)
+	})
+	t.Run("Changes through the client invalidate the object", func(t *testing.T) {
+		thruk, config, closeServer := startFakeConfigServer(CacheOptions{})
+		defer closeServer()
+
+		_, err := thruk.GetHost("h1")
+		assert.NilError(t, err)
+		assert.NilError(t, thruk.PatchConfigObject("h1", map[string]interface{}{"alias": "x"}))
+		_, err = thruk.GetHost("h1")
+		assert.NilError(t, err)
+		assert.Equal(t, config.requests, 3)
+	})
+	t.Run("Discard clears the cache", func(t *testing.T) {
+		thruk, _, closeServer := startFakeConfigServer(CacheOptions{})
+		defer closeServer()
+
+		_, err := thruk.WarmConfigCache()
+		assert.NilError(t, err)
+		assert.NilError(t, thruk.DiscardConfigs())
+		assert.Equal(t, thruk.CacheStats().Size, 0)
+	})
+	t.Run("Warm up loads all objects", func(t *testing.T) {
+		thruk, config, closeServer := startFakeConfigServer(CacheOptions{})
+		defer closeServer()
+
+		count, err := thruk.WarmConfigCache()
+		assert.NilError(t, err)
+		assert.Equal(t, count, 3)
+		_, err = thruk.GetCommand("c1")
+		assert.NilError(t, err)
+		_, err = thruk.GetHost("h2")
+		assert.NilError(t, err)
+		assert.Equal(t, config.requests, 1)
+	})
+	t.Run("Objects expire after their type's TTL", func(t *testing.T) {
+		thruk, config, closeServer := startFakeConfigServer(CacheOptions{TTL: time.Hour, TypeTTL: map[string]time.Duration{"host": time.Nanosecond}})
+		defer closeServer()
+
+		_, err := thruk.WarmConfigCache()
+		assert.NilError(t, err)
+		time.Sleep(time.Millisecond)
+		_, err = thruk.GetHost("h1")
+		assert.NilError(t, err)
+		_, err = thruk.GetCommand("c1")
+		assert.NilError(t, err)
+		assert.Equal(t, config.requests, 2)
+	})
+	t.Run("Least recently used objects are evicted", func(t *testing.T) {
+		thruk, _, closeServer := startFakeConfigServer(CacheOptions{MaxObjects: 2})
+		defer closeServer()
+
+		_, err := thruk.WarmConfigCache()
+		assert.NilError(t, err)
+		stats := thruk.CacheStats()
+		assert.Equal(t, stats.Size, 2)
+		assert.Equal(t, stats.Evictions, 1)
+	})
+	t.Run("Clients without cache report it", func(t *testing.T) {
+		thruk := NewThruk("http://localhost", "site", "user", "pass", false)
+
+		_, err := thruk.WarmConfigCache()
+		assert.Equal(t, err, ErrorCacheDisabled)
+	})
+}
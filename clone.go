@@ -0,0 +1,142 @@
+package thruk
+
+import (
+	"net/url"
+	"regexp"
+)
+
+type CloneOptions struct {
+	// FILE the copies are written to, the source file when empty.
+	FILE string
+}
+
+type CloneResult struct {
+	HostID     string
+	ServiceIDs []string
+	// GroupIDs are the host- and servicegroups the clone was added to.
+	GroupIDs []string
+}
+
+var fileLineSuffix = regexp.MustCompile(`:\d+$`)
+
+// copyObject returns a copy of object without the attributes Thruk assigns
+// on creation.
+func copyObject(object rawObject, file string) rawObject {
+	copied := rawObject{}
+	for attribute, value := range object {
+		copied[attribute] = value
+	}
+	delete(copied, ":ID")
+	delete(copied, ":PEER_KEY")
+	delete(copied, ":READONLY")
+	if file == "" {
+		file = fileLineSuffix.ReplaceAllString(object.str(":FILE"), "")
+	}
+	copied[":FILE"] = file
+	return copied
+}
+
+// CloneHost creates a copy of a host and of every service bound to it by
+// host_name, including custom variables and group memberships from both
+// the object and the group side. Services applied through a hostgroup
+// follow the copied hostgroups. On error the objects created so far are
+// returned and stay staged; use DiscardConfigs to drop them.
+func (t Thruk) CloneHost(sourceID, newName, newAddress string, opts CloneOptions) (CloneResult, error) {
+	result := CloneResult{}
+	if sourceID == "" || newName == "" {
+		return result, ErrorInvalidInput
+	}
+	hosts, err := t.listRawConfigObjects(url.Values{":TYPE": {"host"}, ":ID": {sourceID}})
+	if err != nil {
+		return result, err
+	}
+	if len(hosts) == 0 {
+		return result, ErrorObjectNotFound
+	}
+	source := hosts[0]
+	sourceName := source.str("host_name")
+	if sourceName == "" {
+		return result, ErrorInvalidInput
+	}
+	if _, err := t.GetHostByName(newName); err != ErrorObjectNotFound {
+		if err == nil {
+			return result, ErrorNameTaken
+		}
+		return result, err
+	}
+
+	host := copyObject(source, opts.FILE)
+	host["host_name"] = newName
+	if newAddress != "" {
+		host["address"] = newAddress
+	}
+	for _, attribute := range []string{"alias", "display_name"} {
+		if host.str(attribute) == sourceName {
+			host[attribute] = newName
+		}
+	}
+	result.HostID, err = t.createConfigObject(host)
+	if err != nil {
+		return result, err
+	}
+
+	services, err := t.listRawConfigObjects(url.Values{":TYPE": {"service"}})
+	if err != nil {
+		return result, err
+	}
+	var descriptions []string
+	for _, service := range services {
+		if !contains(service.list("host_name"), sourceName) {
+			continue
+		}
+		clone := copyObject(service, opts.FILE)
+		clone.setList("host_name", []string{newName})
+		delete(clone, "hostgroup_name")
+		id, err := t.createConfigObject(clone)
+		if err != nil {
+			return result, err
+		}
+		result.ServiceIDs = append(result.ServiceIDs, id)
+		descriptions = append(descriptions, service.str("service_description"))
+	}
+
+	hostgroups, err := t.listRawConfigObjects(url.Values{":TYPE": {"hostgroup"}})
+	if err != nil {
+		return result, err
+	}
+	for _, group := range hostgroups {
+		members := group.list("members")
+		if !contains(members, sourceName) || contains(members, newName) {
+			continue
+		}
+		err := t.PatchConfigObject(group.id(), map[string]interface{}{"members": append(members, newName)})
+		if err != nil {
+			return result, err
+		}
+		result.GroupIDs = append(result.GroupIDs, group.id())
+	}
+
+	servicegroups, err := t.listRawConfigObjects(url.Values{":TYPE": {"servicegroup"}})
+	if err != nil {
+		return result, err
+	}
+	for _, group := range servicegroups {
+		members := group.list("members")
+		added := false
+		for i := 0; i+1 < len(members); i += 2 {
+			if members[i] == sourceName && contains(descriptions, members[i+1]) {
+				members = append(members, newName, members[i+1])
+				added = true
+			}
+		}
+		if !added {
+			continue
+		}
+		err := t.PatchConfigObject(group.id(), map[string]interface{}{"members": members})
+		if err != nil {
+			return result, err
+		}
+		result.GroupIDs = append(result.GroupIDs, group.id())
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package thruk
+
+import (
+	"gotest.tools/assert"
+	"testing"
+)
+
+func Test_thruk_client_CloneHost(t *testing.T) {
+	t.Run("Clone host of empty id returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.CloneHost("", "web-08", "127.0.0.8", CloneOptions{})
+		assert.Error(t, err, "[ERROR] invalid input")
+	})
+	t.Run("Clone host copies the host and its services", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		hostID, _ := createHostWithService(t, thruk, "web-07")
+		groupID, err := thruk.CreateServicegroup(Servicegroup{
+			FILE:             "references.cfg",
+			TYPE:             "servicegroup",
+			ServicegroupName: "clone-group",
+			Members:          ServiceRefs{{Host: "web-07", Service: "ping"}},
+		})
+		assert.NilError(t, err)
+
+		result, err := thruk.CloneHost(hostID, "web-08", "127.0.0.8", CloneOptions{FILE: "clone.cfg"})
+		assert.NilError(t, err)
+		assert.Equal(t, len(result.ServiceIDs), 1)
+		assert.DeepEqual(t, result.GroupIDs, []string{groupID})
+
+		host, err := thruk.GetHost(result.HostID)
+		assert.NilError(t, err)
+		assert.Equal(t, host.HostName, "web-08")
+		assert.Equal(t, host.Address, "127.0.0.8")
+		assert.DeepEqual(t, host.Use, []string{"generic-host"})
+		service, err := thruk.GetService(result.ServiceIDs[0])
+		assert.NilError(t, err)
+		assert.DeepEqual(t, service.HostName, []string{"web-08"})
+		assert.Equal(t, service.CheckCommand, "check_ping!100,20%!500,60%")
+		group, err := thruk.GetServicegroup(groupID)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, group.Members, ServiceRefs{{Host: "web-07", Service: "ping"}, {Host: "web-08", Service: "ping"}})
+	})
+	t.Run("Clone host to an existing name returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		hostID, _ := createHostWithService(t, thruk, "clone-first")
+		createHostWithService(t, thruk, "clone-second")
+
+		_, err := thruk.CloneHost(hostID, "clone-second", "", CloneOptions{})
+		assert.Error(t, err, "[ERROR] an object of this type already uses the new name")
+	})
+}
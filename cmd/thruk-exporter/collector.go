@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	thruk "gitlab.com/roviluca/thruk-go"
+)
+
+type statusSource interface {
+	ListHostStatus(filter url.Values) ([]thruk.HostStatus, error)
+	ListServiceStatus(filter url.Values) ([]thruk.ServiceStatus, error)
+	ListSites() ([]thruk.Site, error)
+}
+
+type collector struct {
+	thruk    statusSource
+	perfData bool
+}
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+type family struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+type registry struct {
+	families []*family
+	byName   map[string]*family
+}
+
+func (r *registry) add(name, help string, value float64, labels ...string) {
+	if r.byName == nil {
+		r.byName = map[string]*family{}
+	}
+	f, ok := r.byName[name]
+	if !ok {
+		f = &family{name: name, help: help}
+		r.byName[name] = f
+		r.families = append(r.families, f)
+	}
+	f.samples = append(f.samples, sample{labels: labels, value: value})
+}
+
+// write renders all families in the Prometheus text exposition format.
+// Every metric is a gauge.
+func (r *registry) write(w io.Writer) {
+	for _, f := range r.families {
+		fmt.Fprintf(w, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", f.name)
+		for _, s := range f.samples {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, formatLabels(s.labels), formatValue(s.value))
+		}
+	}
+}
+
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+escapeLabelValue(labels[i+1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func (c *collector) collect(w io.Writer) {
+	r := &registry{}
+	up := true
+
+	sites, err := c.thruk.ListSites()
+	if err != nil {
+		log.Printf("listing sites: %s", err)
+		up = false
+	}
+	sort.Slice(sites, func(i, j int) bool { return sites[i].ID < sites[j].ID })
+	for _, site := range sites {
+		r.add("thruk_backend_connected", "Whether Thruk is connected to the backend (peer).",
+			float64(site.Connected), "peer_key", site.ID, "peer_name", site.Name)
+	}
+
+	hosts, err := c.thruk.ListHostStatus(nil)
+	if err != nil {
+		log.Printf("listing hosts: %s", err)
+		up = false
+	}
+	for _, host := range hosts {
+		labels := []string{"host", host.Name, "peer_name", host.PeerName}
+		r.add("thruk_host_state", "Current host state (0=UP, 1=DOWN, 2=UNREACHABLE).", float64(host.State), labels...)
+		r.add("thruk_host_state_type", "Current host state type (0=SOFT, 1=HARD).", float64(host.StateType), labels...)
+		r.add("thruk_host_acknowledged", "Whether the host problem has been acknowledged.", float64(host.Acknowledged), labels...)
+		r.add("thruk_host_in_downtime", "Whether the host is in a scheduled downtime.", boolToFloat(host.ScheduledDowntimeDepth > 0), labels...)
+		r.add("thruk_host_flapping", "Whether the host is flapping.", float64(host.IsFlapping), labels...)
+		r.add("thruk_host_check_latency_seconds", "Latency of the last host check.", host.Latency, labels...)
+		r.add("thruk_host_check_execution_time_seconds", "Execution time of the last host check.", host.ExecutionTime, labels...)
+	}
+
+	services, err := c.thruk.ListServiceStatus(nil)
+	if err != nil {
+		log.Printf("listing services: %s", err)
+		up = false
+	}
+	for _, service := range services {
+		labels := []string{"host", service.HostName, "service", service.Description, "peer_name", service.PeerName}
+		r.add("thruk_service_state", "Current service state (0=OK, 1=WARNING, 2=CRITICAL, 3=UNKNOWN).", float64(service.State), labels...)
+		r.add("thruk_service_state_type", "Current service state type (0=SOFT, 1=HARD).", float64(service.StateType), labels...)
+		r.add("thruk_service_acknowledged", "Whether the service problem has been acknowledged.", float64(service.Acknowledged), labels...)
+		r.add("thruk_service_in_downtime", "Whether the service is in a scheduled downtime.", boolToFloat(service.ScheduledDowntimeDepth > 0), labels...)
+		r.add("thruk_service_flapping", "Whether the service is flapping.", float64(service.IsFlapping), labels...)
+		r.add("thruk_service_check_latency_seconds", "Latency of the last service check.", service.Latency, labels...)
+		r.add("thruk_service_check_execution_time_seconds", "Execution time of the last service check.", service.ExecutionTime, labels...)
+		if !c.perfData {
+			continue
+		}
+		data, _ := thruk.ParsePerfData(service.PerfData)
+		// plugins may repeat a label, which would duplicate the series and
+		// fail the whole scrape, so only the first one is exposed
+		seen := map[string]bool{}
+		for _, datum := range data {
+			if seen[datum.Label] {
+				continue
+			}
+			seen[datum.Label] = true
+			r.add("thruk_service_perfdata_value", "Value reported in the service perfdata.", datum.Value,
+				"host", service.HostName, "service", service.Description, "peer_name", service.PeerName,
+				"label", datum.Label, "uom", datum.UOM)
+		}
+	}
+
+	r.add("thruk_up", "Whether the last query of the Thruk API was successful.", boolToFloat(up))
+	r.write(w)
+}
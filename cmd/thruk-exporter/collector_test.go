@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	thruk "gitlab.com/roviluca/thruk-go"
+	"gotest.tools/assert"
+)
+
+type fakeStatus struct {
+	hosts    []thruk.HostStatus
+	services []thruk.ServiceStatus
+	sites    []thruk.Site
+	err      error
+}
+
+func (f fakeStatus) ListHostStatus(filter url.Values) ([]thruk.HostStatus, error) {
+	return f.hosts, f.err
+}
+
+func (f fakeStatus) ListServiceStatus(filter url.Values) ([]thruk.ServiceStatus, error) {
+	return f.services, f.err
+}
+
+func (f fakeStatus) ListSites() ([]thruk.Site, error) {
+	return f.sites, f.err
+}
+
+func Test_collector(t *testing.T) {
+	source := fakeStatus{
+		sites: []thruk.Site{{ID: "48f1c", Name: "demo", Connected: 1}},
+		hosts: []thruk.HostStatus{{Name: "web-07", PeerName: "demo", State: 1, Acknowledged: 1, ScheduledDowntimeDepth: 2}},
+		services: []thruk.ServiceStatus{{
+			HostName:    "web-07",
+			Description: `disk "/"`,
+			PeerName:    "demo",
+			State:       2,
+			Latency:     0.25,
+			PerfData:    "'/ used'=80%;90;95 inodes=12",
+		}},
+	}
+
+	t.Run("exposes host, service and backend gauges", func(t *testing.T) {
+		var buf bytes.Buffer
+		(&collector{thruk: source}).collect(&buf)
+		out := buf.String()
+
+		for _, line := range []string{
+			"# TYPE thruk_host_state gauge",
+			`thruk_backend_connected{peer_key="48f1c",peer_name="demo"} 1`,
+			`thruk_host_state{host="web-07",peer_name="demo"} 1`,
+			`thruk_host_acknowledged{host="web-07",peer_name="demo"} 1`,
+			`thruk_host_in_downtime{host="web-07",peer_name="demo"} 1`,
+			`thruk_service_state{host="web-07",service="disk \"/\"",peer_name="demo"} 2`,
+			`thruk_service_check_latency_seconds{host="web-07",service="disk \"/\"",peer_name="demo"} 0.25`,
+			"thruk_up 1",
+		} {
+			assert.Assert(t, strings.Contains(out, line+"\n"), "missing %q in\n%s", line, out)
+		}
+		assert.Assert(t, !strings.Contains(out, "thruk_service_perfdata_value"))
+	})
+	t.Run("exposes perfdata only when enabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		(&collector{thruk: source, perfData: true}).collect(&buf)
+		out := buf.String()
+
+		assert.Assert(t, strings.Contains(out, `thruk_service_perfdata_value{host="web-07",service="disk \"/\"",peer_name="demo",label="/ used",uom="%"} 80`+"\n"))
+		assert.Assert(t, strings.Contains(out, `thruk_service_perfdata_value{host="web-07",service="disk \"/\"",peer_name="demo",label="inodes",uom=""} 12`+"\n"))
+	})
+	t.Run("exposes the first of repeated perfdata labels", func(t *testing.T) {
+		repeated := fakeStatus{services: []thruk.ServiceStatus{
+			{HostName: "web-07", Description: "load", PeerName: "demo", PerfData: "a=1 a=2s b=3"},
+			{HostName: "web-08", Description: "load", PeerName: "demo", PerfData: "a=4"},
+		}}
+		var buf bytes.Buffer
+		(&collector{thruk: repeated, perfData: true}).collect(&buf)
+		out := buf.String()
+
+		assert.Equal(t, strings.Count(out, `thruk_service_perfdata_value{host="web-07",service="load",peer_name="demo",label="a"`), 1, out)
+		assert.Assert(t, strings.Contains(out, `thruk_service_perfdata_value{host="web-07",service="load",peer_name="demo",label="a",uom=""} 1`+"\n"))
+		assert.Assert(t, strings.Contains(out, `thruk_service_perfdata_value{host="web-07",service="load",peer_name="demo",label="b",uom=""} 3`+"\n"))
+		assert.Assert(t, strings.Contains(out, `thruk_service_perfdata_value{host="web-08",service="load",peer_name="demo",label="a",uom=""} 4`+"\n"))
+	})
+	t.Run("reports thruk_up 0 when the API fails", func(t *testing.T) {
+		var buf bytes.Buffer
+		(&collector{thruk: fakeStatus{err: errors.New("connection refused")}}).collect(&buf)
+
+		assert.Assert(t, strings.Contains(buf.String(), "thruk_up 0\n"))
+	})
+}
@@ -0,0 +1,40 @@
+// Command thruk-exporter exposes the host, service and backend status known
+// to Thruk as Prometheus metrics.
+//
+// Status is fetched from the Thruk REST API on every scrape, so the
+// scrape_interval configured in Prometheus is the polling interval.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	thruk "gitlab.com/roviluca/thruk-go"
+)
+
+func main() {
+	listenAddress := flag.String("web.listen-address", ":9836", "address to expose metrics on")
+	metricsPath := flag.String("web.telemetry-path", "/metrics", "path under which to expose metrics")
+	thrukURL := flag.String("thruk.url", "https://localhost", "base URL of the Thruk server")
+	siteName := flag.String("thruk.site", "demo", "OMD site name")
+	username := flag.String("thruk.username", "", "username for basic auth, the password is read from THRUK_PASSWORD")
+	skipTLS := flag.Bool("thruk.insecure-skip-verify", false, "do not verify the Thruk TLS certificate")
+	perfData := flag.Bool("collect.perfdata", false, "export parsed service perfdata values")
+	flag.Parse()
+
+	client := thruk.NewThruk(*thrukURL, *siteName, *username, os.Getenv("THRUK_PASSWORD"), *skipTLS)
+	collector := &collector{thruk: client, perfData: *perfData}
+
+	http.HandleFunc(*metricsPath, func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		collector.collect(&buf)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(buf.Bytes())
+	})
+
+	log.Printf("listening on %s", *listenAddress)
+	log.Fatal(http.ListenAndServe(*listenAddress, nil))
+}
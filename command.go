@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"net/url"
 )
 
 var ErrorNeedFileTypeCommandName = errors.New("[ERROR] FILE, TYPE and CommandName must not be empty")
@@ -23,12 +24,10 @@ func (t Thruk) GetCommand(id string) (Command, error) {
 	if id == "" {
 		return Command{}, ErrorInvalidInput
 	}
-	resp, err := t.GetURL("/" + t.SiteName + "/thruk/r/config/objects?:TYPE=command&:ID=" + id)
-	failOnError(err)
-	defer resp.Body.Close()
-
-	err = json.NewDecoder(resp.Body).Decode(&commands)
-	failOnError(err)
+	err := t.findConfigObjects(url.Values{":TYPE": {"command"}, ":ID": {id}}, &commands)
+	if err != nil {
+		return Command{}, err
+	}
 	if len(commands) == 0 {
 		return Command{}, ErrorObjectNotFound
 	}
@@ -63,6 +62,32 @@ func (t Thruk) CreateCommand(command Command) (string, error) {
 	return thrukResp.Objects[0].ID, err
 }
 
+func (t Thruk) GetCommandByName(name string) (Command, error) {
+	var commands []Command
+	if name == "" {
+		return Command{}, ErrorInvalidInput
+	}
+	err := t.findConfigObjects(url.Values{":TYPE": {"command"}, "command_name": {name}}, &commands)
+	if err != nil {
+		return Command{}, err
+	}
+	if len(commands) == 0 {
+		return Command{}, ErrorObjectNotFound
+	}
+	if len(commands) > 1 {
+		return Command{}, ErrorAmbiguousName
+	}
+
+	return commands[0], nil
+}
+
+func (t Thruk) UpdateCommand(command Command) error {
+	if command.ID == "" {
+		return ErrorNeedID
+	}
+	return t.replaceConfigObject(command.ID, command)
+}
+
 //
 func (t Thruk) DeleteCommand(id string) error {
 	URL := "/" + t.SiteName + "/thruk/r/config/objects/" + id
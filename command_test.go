@@ -105,4 +105,40 @@ func Test_thruk_client_Command(t *testing.T) {
 		_, err := thruk.GetCommand(id)
 		assert.Error(t, err, "[ERROR] Object not found")
 	})
+	t.Run("Get command by name returns command", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateCommand(Command{
+			FILE:        "test.cfg",
+			TYPE:        "command",
+			CommandName: "check_by_name",
+			CommandLine: "hostname",
+		})
+		assert.NilError(t, err)
+
+		object, err := thruk.GetCommandByName("check_by_name")
+		assert.NilError(t, err)
+		assert.Equal(t, object.ID, id)
+	})
+	t.Run("Update command replaces its attributes", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateCommand(Command{
+			FILE:        "test.cfg",
+			TYPE:        "command",
+			CommandName: "check_update",
+			CommandLine: "hostname",
+		})
+		assert.NilError(t, err)
+		command, err := thruk.GetCommand(id)
+		assert.NilError(t, err)
+
+		command.CommandLine = "uptime"
+		err = thruk.UpdateCommand(command)
+		assert.NilError(t, err)
+
+		updated, err := thruk.GetCommand(id)
+		assert.NilError(t, err)
+		assert.Equal(t, updated.CommandLine, "uptime")
+	})
 }
@@ -0,0 +1,84 @@
+package thruk
+
+import (
+	"net/url"
+)
+
+type Contact struct {
+	FILE                        string   `json:":FILE"`
+	ID                          string   `json:":ID,omitempty"`
+	PEERKEY                     string   `json:":PEER_KEY,omitempty"`
+	READONLY                    int      `json:":READONLY,omitempty"`
+	TYPE                        string   `json:":TYPE"`
+	Alias                       string   `json:"alias,omitempty"`
+	CanSubmitCommands           string   `json:"can_submit_commands,omitempty"`
+	ContactName                 string   `json:"contact_name,omitempty"`
+	Contactgroups               []string `json:"contactgroups,omitempty"`
+	Email                       string   `json:"email,omitempty"`
+	HostNotificationCommands    []string `json:"host_notification_commands,omitempty"`
+	HostNotificationOptions     []string `json:"host_notification_options,omitempty"`
+	HostNotificationPeriod      string   `json:"host_notification_period,omitempty"`
+	HostNotificationsEnabled    string   `json:"host_notifications_enabled,omitempty"`
+	Name                        string   `json:"name,omitempty"`
+	Pager                       string   `json:"pager,omitempty"`
+	Register                    string   `json:"register,omitempty"`
+	ServiceNotificationCommands []string `json:"service_notification_commands,omitempty"`
+	ServiceNotificationOptions  []string `json:"service_notification_options,omitempty"`
+	ServiceNotificationPeriod   string   `json:"service_notification_period,omitempty"`
+	ServiceNotificationsEnabled string   `json:"service_notifications_enabled,omitempty"`
+	Use                         []string `json:"use,omitempty"`
+}
+
+func (t Thruk) GetContact(id string) (Contact, error) {
+	var contacts []Contact
+	if id == "" {
+		return Contact{}, ErrorInvalidInput
+	}
+	err := t.findConfigObjects(url.Values{":TYPE": {"contact"}, ":ID": {id}}, &contacts)
+	if err != nil {
+		return Contact{}, err
+	}
+	if len(contacts) == 0 {
+		return Contact{}, ErrorObjectNotFound
+	}
+
+	return contacts[0], nil
+}
+
+func (t Thruk) GetContactByName(name string) (Contact, error) {
+	var contacts []Contact
+	if name == "" {
+		return Contact{}, ErrorInvalidInput
+	}
+	err := t.findConfigObjects(url.Values{":TYPE": {"contact"}, "contact_name": {name}}, &contacts)
+	if err != nil {
+		return Contact{}, err
+	}
+	if len(contacts) == 0 {
+		return Contact{}, ErrorObjectNotFound
+	}
+	if len(contacts) > 1 {
+		return Contact{}, ErrorAmbiguousName
+	}
+
+	return contacts[0], nil
+}
+
+func (t Thruk) CreateContact(contact Contact) (string, error) {
+	if contact.FILE == "" || contact.TYPE == "" {
+		return "", ErrorNeedFileAndType
+	}
+	return t.createConfigObject(contact)
+}
+
+func (t Thruk) UpdateContact(contact Contact) error {
+	if contact.ID == "" {
+		return ErrorNeedID
+	}
+	return t.replaceConfigObject(contact.ID, contact)
+}
+
+func (t Thruk) DeleteContact(id string) error {
+	URL := "/" + t.SiteName + "/thruk/r/config/objects/" + id
+	return t.DeleteURL(URL)
+}
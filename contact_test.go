@@ -0,0 +1,103 @@
+package thruk
+
+import (
+	"gotest.tools/assert"
+	"testing"
+)
+
+func Test_thruk_client_Contact(t *testing.T) {
+	t.Run("Get contact of empty id returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		object, err := thruk.GetContact("")
+		assert.Error(t, err, "[ERROR] invalid input")
+		assert.DeepEqual(t, object, Contact{})
+	})
+	t.Run("Create contact returns error when FILE and TYPE are empty", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.CreateContact(Contact{})
+		assert.Error(t, err, "[ERROR] FILE and TYPE must not be empty")
+	})
+	t.Run("Create contact returns nil error and ID on success", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateContact(Contact{
+			FILE:        "test.cfg",
+			TYPE:        "contact",
+			ContactName: "test_contact",
+		})
+		assert.NilError(t, err)
+		if id == "" {
+			t.Log("Create returned nil ID")
+			t.FailNow()
+		}
+		createdObject, err := thruk.GetContact(id)
+		assert.NilError(t, err)
+
+		assert.Equal(t, id, createdObject.ID)
+	})
+	t.Run("Get contact by name returns contact", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateContact(Contact{
+			FILE:        "test.cfg",
+			TYPE:        "contact",
+			ContactName: "test_contact_by_name",
+		})
+		assert.NilError(t, err)
+
+		object, err := thruk.GetContactByName("test_contact_by_name")
+		assert.NilError(t, err)
+		assert.Equal(t, object.ID, id)
+	})
+	t.Run("Get contact by unknown name returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.GetContactByName("not_existent")
+		assert.Error(t, err, "[ERROR] Object not found")
+	})
+	t.Run("Update contact replaces its attributes", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateContact(Contact{
+			FILE:        "test.cfg",
+			TYPE:        "contact",
+			ContactName: "test_contact_update",
+			Alias:       "before",
+		})
+		assert.NilError(t, err)
+		object, err := thruk.GetContact(id)
+		assert.NilError(t, err)
+
+		object.Alias = "after"
+		err = thruk.UpdateContact(object)
+		assert.NilError(t, err)
+
+		updated, err := thruk.GetContact(id)
+		assert.NilError(t, err)
+		assert.Equal(t, updated.Alias, "after")
+	})
+	t.Run("Update contact without ID returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		err := thruk.UpdateContact(Contact{})
+		assert.Error(t, err, "[ERROR] ID must not be empty")
+	})
+	t.Run("Delete contact must remove an object that exists", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, _ := thruk.CreateContact(Contact{
+			FILE: "test.cfg",
+			TYPE: "contact",
+		})
+		if id == "" {
+			t.Fatal("failed to create object")
+		}
+		err := thruk.DeleteContact(id)
+		assert.NilError(t, err)
+		thruk.SaveConfigs()
+		_, err = thruk.GetContact(id)
+		assert.Error(t, err, "[ERROR] Object not found")
+	})
+}
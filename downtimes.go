@@ -0,0 +1,33 @@
+package thruk
+
+import (
+	"context"
+	"net/url"
+)
+
+// Downtime is a scheduled downtime of a host, or of a service if
+// ServiceDescription is set.
+type Downtime struct {
+	ID                 int64  `json:"id"`
+	HostName           string `json:"host_name"`
+	ServiceDescription string `json:"service_description,omitempty"`
+	Author             string `json:"author"`
+	Comment            string `json:"comment"`
+	EntryTime          int64  `json:"entry_time"`
+	StartTime          int64  `json:"start_time"`
+	EndTime            int64  `json:"end_time"`
+	Fixed              int    `json:"fixed"`
+	Duration           int64  `json:"duration"`
+	TriggeredBy        int64  `json:"triggered_by"`
+	IsService          int    `json:"is_service"`
+	PeerKey            string `json:"peer_key,omitempty"`
+}
+
+func (t Thruk) ListDowntimes(filter url.Values) ([]Downtime, error) {
+	var downtimes []Downtime
+	err := t.listAll(context.Background(), "downtimes", filter, &downtimes)
+	if err != nil {
+		return nil, err
+	}
+	return downtimes, nil
+}
@@ -0,0 +1,338 @@
+package thruk
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"sync"
+)
+
+var ErrorWrongObjectType = errors.New("[ERROR] object has the wrong type")
+
+// objectLocks serializes read-modify-write cycles on the same objects, so
+// concurrent membership changes through this package do not overwrite each
+// other. Thruk itself has no way to detect lost updates. Entries are
+// removed again once no one holds or waits for them.
+var objectLocks = struct {
+	sync.Mutex
+	locks map[string]*objectLock
+}{locks: map[string]*objectLock{}}
+
+type objectLock struct {
+	sync.Mutex
+	// users counts the holders and waiters of the lock, guarded by
+	// objectLocks
+	users int
+}
+
+func (t Thruk) lockObjects(ids ...string) func() {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = t.URL + "/" + t.SiteName + "/" + id
+	}
+	sort.Strings(keys)
+
+	var held []string
+	for i, key := range keys {
+		if i > 0 && keys[i-1] == key {
+			continue
+		}
+		objectLocks.Lock()
+		lock, ok := objectLocks.locks[key]
+		if !ok {
+			lock = &objectLock{}
+			objectLocks.locks[key] = lock
+		}
+		lock.users++
+		objectLocks.Unlock()
+		lock.Lock()
+		held = append(held, key)
+	}
+	return func() {
+		objectLocks.Lock()
+		defer objectLocks.Unlock()
+		for i := len(held) - 1; i >= 0; i-- {
+			lock := objectLocks.locks[held[i]]
+			lock.users--
+			if lock.users == 0 {
+				delete(objectLocks.locks, held[i])
+			}
+			lock.Unlock()
+		}
+	}
+}
+
+// uncached returns a copy of the client that reads past the config cache,
+// for read-modify-write cycles that must not start from stale objects.
+// Changes still go through t, which invalidates the cached objects.
+func (t Thruk) uncached() Thruk {
+	t.cache = nil
+	return t
+}
+
+func (t Thruk) getRawObject(id, objectType string) (rawObject, error) {
+	if id == "" {
+		return nil, ErrorInvalidInput
+	}
+	objects, err := t.listRawConfigObjects(url.Values{":ID": {id}})
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, ErrorObjectNotFound
+	}
+	if objects[0].objectType() != objectType {
+		return nil, ErrorWrongObjectType
+	}
+	return objects[0], nil
+}
+
+func dedupe(values []string) []string {
+	var unique []string
+	for _, value := range values {
+		if !contains(unique, value) {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+func without(values []string, value string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+func listValue(values []string) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// AddToHostgroup makes a host member of a hostgroup by adding the group to
+// the host's hostgroups. Nothing changes if the host is already a member
+// through either the host or the group definition. Concurrent calls of this
+// package are serialized, but writers in other processes or Thruk's web UI
+// can still overwrite each other's changes.
+func (t Thruk) AddToHostgroup(hostID, hostgroupID string) error {
+	defer t.lockObjects(hostID, hostgroupID)()
+	host, err := t.uncached().getRawObject(hostID, "host")
+	if err != nil {
+		return err
+	}
+	group, err := t.uncached().getRawObject(hostgroupID, "hostgroup")
+	if err != nil {
+		return err
+	}
+	groupName := group.str("hostgroup_name")
+	if contains(host.list("hostgroups"), groupName) || contains(group.list("members"), host.str("host_name")) {
+		return nil
+	}
+	hostgroups := dedupe(append(host.list("hostgroups"), groupName))
+	return t.PatchConfigObject(hostID, map[string]interface{}{"hostgroups": hostgroups})
+}
+
+// RemoveFromHostgroup removes a host from a hostgroup on both the host and
+// the group side.
+func (t Thruk) RemoveFromHostgroup(hostID, hostgroupID string) error {
+	defer t.lockObjects(hostID, hostgroupID)()
+	host, err := t.uncached().getRawObject(hostID, "host")
+	if err != nil {
+		return err
+	}
+	group, err := t.uncached().getRawObject(hostgroupID, "hostgroup")
+	if err != nil {
+		return err
+	}
+	if hostgroups := host.list("hostgroups"); contains(hostgroups, group.str("hostgroup_name")) {
+		kept := without(hostgroups, group.str("hostgroup_name"))
+		if err := t.PatchConfigObject(hostID, map[string]interface{}{"hostgroups": listValue(kept)}); err != nil {
+			return err
+		}
+	}
+	if members := group.list("members"); contains(members, host.str("host_name")) {
+		kept := without(members, host.str("host_name"))
+		return t.PatchConfigObject(hostgroupID, map[string]interface{}{"members": listValue(kept)})
+	}
+	return nil
+}
+
+func servicegroupHasService(group, service rawObject) bool {
+	members := group.list("members")
+	for i := 0; i+1 < len(members); i += 2 {
+		if members[i+1] == service.str("service_description") && contains(service.list("host_name"), members[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddToServicegroup makes a service member of a servicegroup by adding the
+// group to the service's servicegroups. Nothing changes if the service is
+// already a member through either definition.
+func (t Thruk) AddToServicegroup(serviceID, servicegroupID string) error {
+	defer t.lockObjects(serviceID, servicegroupID)()
+	service, err := t.uncached().getRawObject(serviceID, "service")
+	if err != nil {
+		return err
+	}
+	group, err := t.uncached().getRawObject(servicegroupID, "servicegroup")
+	if err != nil {
+		return err
+	}
+	groupName := group.str("servicegroup_name")
+	if contains(service.list("servicegroups"), groupName) || servicegroupHasService(group, service) {
+		return nil
+	}
+	servicegroups := dedupe(append(service.list("servicegroups"), groupName))
+	return t.PatchConfigObject(serviceID, map[string]interface{}{"servicegroups": servicegroups})
+}
+
+// RemoveFromServicegroup removes a service from a servicegroup on both the
+// service and the group side.
+func (t Thruk) RemoveFromServicegroup(serviceID, servicegroupID string) error {
+	defer t.lockObjects(serviceID, servicegroupID)()
+	service, err := t.uncached().getRawObject(serviceID, "service")
+	if err != nil {
+		return err
+	}
+	group, err := t.uncached().getRawObject(servicegroupID, "servicegroup")
+	if err != nil {
+		return err
+	}
+	if servicegroups := service.list("servicegroups"); contains(servicegroups, group.str("servicegroup_name")) {
+		kept := without(servicegroups, group.str("servicegroup_name"))
+		if err := t.PatchConfigObject(serviceID, map[string]interface{}{"servicegroups": listValue(kept)}); err != nil {
+			return err
+		}
+	}
+	if !servicegroupHasService(group, service) {
+		return nil
+	}
+	members := group.list("members")
+	var kept []string
+	for i := 0; i+1 < len(members); i += 2 {
+		if members[i+1] == service.str("service_description") && contains(service.list("host_name"), members[i]) {
+			continue
+		}
+		kept = append(kept, members[i], members[i+1])
+	}
+	return t.PatchConfigObject(servicegroupID, map[string]interface{}{"members": listValue(kept)})
+}
+
+// SetGroupMembers replaces the members of a host- or servicegroup. Members
+// are host names for hostgroups and alternating host and service names for
+// servicegroups. Objects that joined the group through their own
+// hostgroups or servicegroups attribute and are not in members are removed
+// from it there as well. Like AddToHostgroup it does not guard against
+// concurrent writers in other processes.
+func (t Thruk) SetGroupMembers(groupID string, members []string) error {
+	if groupID == "" {
+		return ErrorInvalidInput
+	}
+	// The objects to take the group from are only known after reading
+	// them. They are locked together with the group, which keeps the lock
+	// order of the other helpers, and read again until no other object
+	// needs a change.
+	var locked []string
+	for {
+		unlock := t.lockObjects(append([]string{groupID}, locked...)...)
+		needed, err := t.replaceGroupMembers(groupID, members, locked)
+		unlock()
+		if err != nil || needed == nil {
+			return err
+		}
+		locked = needed
+	}
+}
+
+// replaceGroupMembers does the work of SetGroupMembers with the group and
+// the locked objects locked. It changes nothing and returns the objects to
+// lock if other objects need a change.
+func (t Thruk) replaceGroupMembers(groupID string, members []string, locked []string) ([]string, error) {
+	fresh := t.uncached()
+	objects, err := fresh.listRawConfigObjects(url.Values{":ID": {groupID}})
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, ErrorObjectNotFound
+	}
+	group := objects[0]
+
+	var memberType, groupsAttribute string
+	switch group.objectType() {
+	case "hostgroup":
+		memberType, groupsAttribute = "host", "hostgroups"
+		members = dedupe(members)
+	case "servicegroup":
+		if len(members)%2 != 0 {
+			return nil, ErrorInvalidInput
+		}
+		memberType, groupsAttribute = "service", "servicegroups"
+		var pairs []string
+		for i := 0; i < len(members); i += 2 {
+			if !containsPair(pairs, members[i], members[i+1]) {
+				pairs = append(pairs, members[i], members[i+1])
+			}
+		}
+		members = pairs
+	default:
+		return nil, ErrorWrongObjectType
+	}
+	groupName := group.str(nameAttributes[group.objectType()])
+
+	candidates, err := fresh.listRawConfigObjects(url.Values{":TYPE": {memberType}})
+	if err != nil {
+		return nil, err
+	}
+	var leaving []rawObject
+	var needed []string
+	missing := false
+	for _, object := range candidates {
+		if !contains(object.list(groupsAttribute), groupName) || isListedMember(object, members) {
+			continue
+		}
+		leaving = append(leaving, object)
+		needed = append(needed, object.id())
+		missing = missing || !contains(locked, object.id())
+	}
+	if missing {
+		return needed, nil
+	}
+
+	for _, object := range leaving {
+		kept := without(object.list(groupsAttribute), groupName)
+		if err := t.PatchConfigObject(object.id(), map[string]interface{}{groupsAttribute: listValue(kept)}); err != nil {
+			return nil, err
+		}
+	}
+	return nil, t.PatchConfigObject(groupID, map[string]interface{}{"members": listValue(members)})
+}
+
+func containsPair(pairs []string, host, service string) bool {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i] == host && pairs[i+1] == service {
+			return true
+		}
+	}
+	return false
+}
+
+// isListedMember reports whether a host or service is covered by members.
+func isListedMember(object rawObject, members []string) bool {
+	if object.objectType() == "host" {
+		return contains(members, object.str("host_name"))
+	}
+	for _, host := range object.list("host_name") {
+		if containsPair(members, host, object.str("service_description")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package thruk
+
+import (
+	"fmt"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_thruk_client_group_membership(t *testing.T) {
+	t.Run("Add to hostgroup adds the group to the host once", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		hostID, _ := createHostWithService(t, thruk, "member-host")
+		groupID, err := thruk.CreateHostgroup(Hostgroup{FILE: "groups.cfg", TYPE: "hostgroup", HostgroupName: "member-group"})
+		assert.NilError(t, err)
+
+		assert.NilError(t, thruk.AddToHostgroup(hostID, groupID))
+		assert.NilError(t, thruk.AddToHostgroup(hostID, groupID))
+
+		host, err := thruk.GetHost(hostID)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, host.Hostgroups, []string{"member-group"})
+	})
+	t.Run("Add to hostgroup with a service id returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, serviceID := createHostWithService(t, thruk, "member-host")
+		groupID, err := thruk.CreateHostgroup(Hostgroup{FILE: "groups.cfg", TYPE: "hostgroup", HostgroupName: "member-group"})
+		assert.NilError(t, err)
+
+		err = thruk.AddToHostgroup(serviceID, groupID)
+		assert.Error(t, err, "[ERROR] object has the wrong type")
+	})
+	t.Run("Remove from hostgroup removes both sides of the membership", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		hostID, err := thruk.CreateHost(Host{FILE: "groups.cfg", TYPE: "host", HostName: "both-sides", Hostgroups: []string{"both-group"}})
+		assert.NilError(t, err)
+		groupID, err := thruk.CreateHostgroup(Hostgroup{FILE: "groups.cfg", TYPE: "hostgroup", HostgroupName: "both-group", Members: []string{"both-sides", "other"}})
+		assert.NilError(t, err)
+
+		assert.NilError(t, thruk.RemoveFromHostgroup(hostID, groupID))
+
+		host, err := thruk.GetHost(hostID)
+		assert.NilError(t, err)
+		assert.Equal(t, len(host.Hostgroups), 0)
+		group, err := thruk.GetHostgroup(groupID)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, group.Members, []string{"other"})
+	})
+	t.Run("Add and remove service from servicegroup", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, serviceID := createHostWithService(t, thruk, "member-host")
+		groupID, err := thruk.CreateServicegroup(Servicegroup{FILE: "groups.cfg", TYPE: "servicegroup", ServicegroupName: "member-services", Members: ServiceRefs{{Host: "member-host", Service: "ping"}}})
+		assert.NilError(t, err)
+
+		assert.NilError(t, thruk.AddToServicegroup(serviceID, groupID))
+		service, err := thruk.GetService(serviceID)
+		assert.NilError(t, err)
+		assert.Equal(t, len(service.Servicegroups), 0)
+
+		assert.NilError(t, thruk.RemoveFromServicegroup(serviceID, groupID))
+		group, err := thruk.GetServicegroup(groupID)
+		assert.NilError(t, err)
+		assert.Equal(t, len(group.Members), 0)
+	})
+	t.Run("Set group members replaces members and drops object side memberships", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		hostID, err := thruk.CreateHost(Host{FILE: "groups.cfg", TYPE: "host", HostName: "dropped", Hostgroups: []string{"set-group"}})
+		assert.NilError(t, err)
+		groupID, err := thruk.CreateHostgroup(Hostgroup{FILE: "groups.cfg", TYPE: "hostgroup", HostgroupName: "set-group"})
+		assert.NilError(t, err)
+
+		assert.NilError(t, thruk.SetGroupMembers(groupID, []string{"a", "b", "a"}))
+
+		group, err := thruk.GetHostgroup(groupID)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, group.Members, []string{"a", "b"})
+		host, err := thruk.GetHost(hostID)
+		assert.NilError(t, err)
+		assert.Equal(t, len(host.Hostgroups), 0)
+	})
+	t.Run("Concurrent additions to the same host are all kept", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		hostID, _ := createHostWithService(t, thruk, "busy-host")
+		var groupIDs []string
+		for i := 0; i < 5; i++ {
+			id, err := thruk.CreateHostgroup(Hostgroup{FILE: "groups.cfg", TYPE: "hostgroup", HostgroupName: fmt.Sprintf("busy-%d", i)})
+			assert.NilError(t, err)
+			groupIDs = append(groupIDs, id)
+		}
+
+		var wg sync.WaitGroup
+		for _, groupID := range groupIDs {
+			wg.Add(1)
+			go func(groupID string) {
+				defer wg.Done()
+				assert.NilError(t, thruk.AddToHostgroup(hostID, groupID))
+			}(groupID)
+		}
+		wg.Wait()
+
+		host, err := thruk.GetHost(hostID)
+		assert.NilError(t, err)
+		assert.Equal(t, len(host.Hostgroups), 5)
+	})
+}
+
+func Test_lockObjects(t *testing.T) {
+	t.Run("Locks are serialized and removed once released", func(t *testing.T) {
+		thruk := Thruk{URL: "http://locks.example", SiteName: "site"}
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		holding := 0
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				unlock := thruk.lockObjects("h1", fmt.Sprintf("g%d", i%3), "h1")
+				mu.Lock()
+				holding++
+				assert.Check(t, holding == 1)
+				mu.Unlock()
+				runtime.Gosched()
+				mu.Lock()
+				holding--
+				mu.Unlock()
+				unlock()
+			}(i)
+		}
+		wg.Wait()
+
+		objectLocks.Lock()
+		defer objectLocks.Unlock()
+		for key := range objectLocks.locks {
+			assert.Check(t, !strings.HasPrefix(key, thruk.URL), key)
+		}
+	})
+}
+
+func Test_group_membership(t *testing.T) {
+	startServer := func(options ...Option) (*Thruk, *fakeConfig, func()) {
+		config := &fakeConfig{objects: []map[string]interface{}{
+			{":ID": "h1", ":TYPE": "host", "host_name": "web01"},
+			{":ID": "h2", ":TYPE": "host", "host_name": "web02", "hostgroups": []interface{}{"web"}},
+			{":ID": "g1", ":TYPE": "hostgroup", "hostgroup_name": "web"},
+		}}
+		server := httptest.NewServer(config)
+		return NewThruk(server.URL, "site", "user", "pass", false, options...), config, server.Close
+	}
+
+	t.Run("Membership changes read past the config cache", func(t *testing.T) {
+		thruk, config, closeServer := startServer(WithConfigCache(CacheOptions{}))
+		defer closeServer()
+
+		_, err := thruk.GetHost("h1")
+		assert.NilError(t, err)
+		config.Lock()
+		config.objects[0]["hostgroups"] = []interface{}{"db"}
+		config.Unlock()
+
+		assert.NilError(t, thruk.AddToHostgroup("h1", "g1"))
+		assert.DeepEqual(t, config.objects[0]["hostgroups"], []interface{}{"db", "web"})
+	})
+	t.Run("Set group members removes the group from other objects", func(t *testing.T) {
+		thruk, config, closeServer := startServer()
+		defer closeServer()
+
+		assert.NilError(t, thruk.SetGroupMembers("g1", []string{"web01"}))
+		assert.Equal(t, config.objects[1]["hostgroups"], nil)
+		assert.DeepEqual(t, config.objects[2]["members"], []interface{}{"web01"})
+	})
+	t.Run("Concurrent membership changes finish", func(t *testing.T) {
+		thruk, _, closeServer := startServer()
+		defer closeServer()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					assert.Check(t, thruk.SetGroupMembers("g1", []string{"web01"}))
+				}()
+				go func() {
+					defer wg.Done()
+					assert.Check(t, thruk.AddToHostgroup("h2", "g1"))
+				}()
+			}
+			wg.Wait()
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("membership changes did not finish")
+		}
+	})
+}
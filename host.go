@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"net/url"
 )
 
 var ErrorNeedFileTypeHost = errors.New("[ERROR] FILE, TYPE and Name must not be empty")
@@ -68,12 +69,10 @@ func (t Thruk) GetHost(id string) (Host, error) {
 	if id == "" {
 		return Host{}, ErrorInvalidInput
 	}
-	resp, err := t.GetURL("/" + t.SiteName + "/thruk/r/config/objects?:TYPE=host&:ID=" + id)
-	failOnError(err)
-	defer resp.Body.Close()
-
-	err = json.NewDecoder(resp.Body).Decode(&hosts)
-	failOnError(err)
+	err := t.findConfigObjects(url.Values{":TYPE": {"host"}, ":ID": {id}}, &hosts)
+	if err != nil {
+		return Host{}, err
+	}
 	if len(hosts) == 0 {
 		return Host{}, ErrorObjectNotFound
 	}
@@ -106,7 +105,32 @@ func (t Thruk) CreateHost(host Host) (string, error) {
 		return "", errors.New("object not created")
 	}
 	return thrukResp.Objects[0].ID, err
-	return "", nil
+}
+
+func (t Thruk) GetHostByName(name string) (Host, error) {
+	var hosts []Host
+	if name == "" {
+		return Host{}, ErrorInvalidInput
+	}
+	err := t.findConfigObjects(url.Values{":TYPE": {"host"}, "host_name": {name}}, &hosts)
+	if err != nil {
+		return Host{}, err
+	}
+	if len(hosts) == 0 {
+		return Host{}, ErrorObjectNotFound
+	}
+	if len(hosts) > 1 {
+		return Host{}, ErrorAmbiguousName
+	}
+
+	return hosts[0], nil
+}
+
+func (t Thruk) UpdateHost(host Host) error {
+	if host.ID == "" {
+		return ErrorNeedID
+	}
+	return t.replaceConfigObject(host.ID, host)
 }
 
 func (t Thruk) DeleteHost(id string) error {
@@ -93,4 +93,52 @@ func Test_thruk_client_crud_on_Host(t *testing.T) {
 		_, err := thruk.GetHost(id)
 		assert.Error(t, err, "[ERROR] Object not found")
 	})
+	t.Run("Get host by name returns host", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateHost(Host{
+			FILE:     "test.cfg",
+			TYPE:     "host",
+			HostName: "web-07",
+			Address:  "127.0.0.1",
+		})
+		assert.NilError(t, err)
+
+		object, err := thruk.GetHostByName("web-07")
+		assert.NilError(t, err)
+		assert.Equal(t, object.ID, id)
+	})
+	t.Run("Get host by unknown name returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.GetHostByName("not_existent")
+		assert.Error(t, err, "[ERROR] Object not found")
+	})
+	t.Run("Update host replaces its attributes", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateHost(Host{
+			FILE:     "test.cfg",
+			TYPE:     "host",
+			HostName: "web-07",
+			Address:  "127.0.0.1",
+		})
+		assert.NilError(t, err)
+		host, err := thruk.GetHost(id)
+		assert.NilError(t, err)
+
+		host.Address = "127.0.0.2"
+		err = thruk.UpdateHost(host)
+		assert.NilError(t, err)
+
+		updated, err := thruk.GetHost(id)
+		assert.NilError(t, err)
+		assert.Equal(t, updated.Address, "127.0.0.2")
+	})
+	t.Run("Update host without ID returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		err := thruk.UpdateHost(Host{})
+		assert.Error(t, err, "[ERROR] ID must not be empty")
+	})
 }
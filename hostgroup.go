@@ -0,0 +1,77 @@
+package thruk
+
+import (
+	"net/url"
+)
+
+type Hostgroup struct {
+	FILE             string   `json:":FILE"`
+	ID               string   `json:":ID,omitempty"`
+	PEERKEY          string   `json:":PEER_KEY,omitempty"`
+	READONLY         int      `json:":READONLY,omitempty"`
+	TYPE             string   `json:":TYPE"`
+	ActionURL        string   `json:"action_url,omitempty"`
+	Alias            string   `json:"alias,omitempty"`
+	HostgroupMembers []string `json:"hostgroup_members,omitempty"`
+	HostgroupName    string   `json:"hostgroup_name,omitempty"`
+	Members          []string `json:"members,omitempty"`
+	Name             string   `json:"name,omitempty"`
+	Notes            string   `json:"notes,omitempty"`
+	NotesURL         string   `json:"notes_url,omitempty"`
+	Register         string   `json:"register,omitempty"`
+	Use              []string `json:"use,omitempty"`
+}
+
+func (t Thruk) GetHostgroup(id string) (Hostgroup, error) {
+	var hostgroups []Hostgroup
+	if id == "" {
+		return Hostgroup{}, ErrorInvalidInput
+	}
+	err := t.findConfigObjects(url.Values{":TYPE": {"hostgroup"}, ":ID": {id}}, &hostgroups)
+	if err != nil {
+		return Hostgroup{}, err
+	}
+	if len(hostgroups) == 0 {
+		return Hostgroup{}, ErrorObjectNotFound
+	}
+
+	return hostgroups[0], nil
+}
+
+func (t Thruk) GetHostgroupByName(name string) (Hostgroup, error) {
+	var hostgroups []Hostgroup
+	if name == "" {
+		return Hostgroup{}, ErrorInvalidInput
+	}
+	err := t.findConfigObjects(url.Values{":TYPE": {"hostgroup"}, "hostgroup_name": {name}}, &hostgroups)
+	if err != nil {
+		return Hostgroup{}, err
+	}
+	if len(hostgroups) == 0 {
+		return Hostgroup{}, ErrorObjectNotFound
+	}
+	if len(hostgroups) > 1 {
+		return Hostgroup{}, ErrorAmbiguousName
+	}
+
+	return hostgroups[0], nil
+}
+
+func (t Thruk) CreateHostgroup(hostgroup Hostgroup) (string, error) {
+	if hostgroup.FILE == "" || hostgroup.TYPE == "" {
+		return "", ErrorNeedFileAndType
+	}
+	return t.createConfigObject(hostgroup)
+}
+
+func (t Thruk) UpdateHostgroup(hostgroup Hostgroup) error {
+	if hostgroup.ID == "" {
+		return ErrorNeedID
+	}
+	return t.replaceConfigObject(hostgroup.ID, hostgroup)
+}
+
+func (t Thruk) DeleteHostgroup(id string) error {
+	URL := "/" + t.SiteName + "/thruk/r/config/objects/" + id
+	return t.DeleteURL(URL)
+}
@@ -0,0 +1,103 @@
+package thruk
+
+import (
+	"gotest.tools/assert"
+	"testing"
+)
+
+func Test_thruk_client_Hostgroup(t *testing.T) {
+	t.Run("Get hostgroup of empty id returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		object, err := thruk.GetHostgroup("")
+		assert.Error(t, err, "[ERROR] invalid input")
+		assert.DeepEqual(t, object, Hostgroup{})
+	})
+	t.Run("Create hostgroup returns error when FILE and TYPE are empty", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.CreateHostgroup(Hostgroup{})
+		assert.Error(t, err, "[ERROR] FILE and TYPE must not be empty")
+	})
+	t.Run("Create hostgroup returns nil error and ID on success", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateHostgroup(Hostgroup{
+			FILE:          "test.cfg",
+			TYPE:          "hostgroup",
+			HostgroupName: "test_hostgroup",
+		})
+		assert.NilError(t, err)
+		if id == "" {
+			t.Log("Create returned nil ID")
+			t.FailNow()
+		}
+		createdObject, err := thruk.GetHostgroup(id)
+		assert.NilError(t, err)
+
+		assert.Equal(t, id, createdObject.ID)
+	})
+	t.Run("Get hostgroup by name returns hostgroup", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateHostgroup(Hostgroup{
+			FILE:          "test.cfg",
+			TYPE:          "hostgroup",
+			HostgroupName: "test_hostgroup_by_name",
+		})
+		assert.NilError(t, err)
+
+		object, err := thruk.GetHostgroupByName("test_hostgroup_by_name")
+		assert.NilError(t, err)
+		assert.Equal(t, object.ID, id)
+	})
+	t.Run("Get hostgroup by unknown name returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.GetHostgroupByName("not_existent")
+		assert.Error(t, err, "[ERROR] Object not found")
+	})
+	t.Run("Update hostgroup replaces its attributes", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateHostgroup(Hostgroup{
+			FILE:          "test.cfg",
+			TYPE:          "hostgroup",
+			HostgroupName: "test_hostgroup_update",
+			Alias:         "before",
+		})
+		assert.NilError(t, err)
+		object, err := thruk.GetHostgroup(id)
+		assert.NilError(t, err)
+
+		object.Alias = "after"
+		err = thruk.UpdateHostgroup(object)
+		assert.NilError(t, err)
+
+		updated, err := thruk.GetHostgroup(id)
+		assert.NilError(t, err)
+		assert.Equal(t, updated.Alias, "after")
+	})
+	t.Run("Update hostgroup without ID returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		err := thruk.UpdateHostgroup(Hostgroup{})
+		assert.Error(t, err, "[ERROR] ID must not be empty")
+	})
+	t.Run("Delete hostgroup must remove an object that exists", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, _ := thruk.CreateHostgroup(Hostgroup{
+			FILE: "test.cfg",
+			TYPE: "hostgroup",
+		})
+		if id == "" {
+			t.Fatal("failed to create object")
+		}
+		err := thruk.DeleteHostgroup(id)
+		assert.NilError(t, err)
+		thruk.SaveConfigs()
+		_, err = thruk.GetHostgroup(id)
+		assert.Error(t, err, "[ERROR] Object not found")
+	})
+}
@@ -0,0 +1,123 @@
+package thruk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+const defaultPageSize = 1000
+
+var ErrorNoObject = errors.New("[ERROR] iterator is not on an object")
+
+// Iterator pages through the objects of a list endpoint with Thruk's limit
+// and offset parameters, fetching the next page when the current one is
+// used up. Stopping early never fetches the remaining pages.
+//
+//	it := thruk.Iterate(ctx, "services", url.Values{"state": {"2"}})
+//	for it.Next() {
+//		var service ServiceStatus
+//		if err := it.Object(&service); err != nil {
+//			...
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	ctx      context.Context
+	thruk    Thruk
+	path     string
+	query    url.Values
+	pageSize int
+	offset   int
+	page     []json.RawMessage
+	index    int
+	done     bool
+	err      error
+}
+
+// Iterate returns an iterator over the objects of the endpoint at path
+// below /thruk/r/, e.g. "hosts" or "config/objects", matching query. A
+// limit in query disables paging and is passed on as is.
+func (t Thruk) Iterate(ctx context.Context, path string, query url.Values) *Iterator {
+	pageSize := t.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	return &Iterator{ctx: ctx, thruk: t, path: path, query: query, pageSize: pageSize, index: -1}
+}
+
+// Next advances to the next object and reports whether there is one.
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.index++
+	if it.index < len(it.page) {
+		return true
+	}
+	if it.done {
+		return false
+	}
+
+	query := url.Values{}
+	for key, values := range it.query {
+		query[key] = values
+	}
+	if query.Get("limit") == "" {
+		query.Set("limit", strconv.Itoa(it.pageSize))
+		query.Set("offset", strconv.Itoa(it.offset))
+	} else {
+		it.done = true
+	}
+	var page []json.RawMessage
+	it.err = it.thruk.getJSONContext(it.ctx, "/"+it.thruk.SiteName+"/thruk/r/"+it.path+"?"+query.Encode(), &page)
+	if it.err != nil {
+		return false
+	}
+	it.page, it.index = page, 0
+	it.offset += len(page)
+	it.done = it.done || len(page) < it.pageSize
+	return len(page) > 0
+}
+
+// Object decodes the object Next advanced to into v. It returns
+// ErrorNoObject before the first call to Next and after Next returned
+// false.
+func (it *Iterator) Object(v interface{}) error {
+	if it.index < 0 || it.index >= len(it.page) {
+		return ErrorNoObject
+	}
+	return json.Unmarshal(it.page[it.index], v)
+}
+
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// listAll reads all pages of an endpoint into v, which must point to a
+// slice.
+func (t Thruk) listAll(ctx context.Context, path string, query url.Values, v interface{}) error {
+	it := t.Iterate(ctx, path, query)
+	var objects []json.RawMessage
+	for it.Next() {
+		objects = append(objects, it.page[it.index])
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	return decodeRawList(objects, v)
+}
+
+func decodeRawList(objects []json.RawMessage, v interface{}) error {
+	raw := make([][]byte, len(objects))
+	for i, object := range objects {
+		raw[i] = object
+	}
+	all := append([]byte("["), bytes.Join(raw, []byte(","))...)
+	return json.Unmarshal(append(all, ']'), v)
+}
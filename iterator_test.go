@@ -0,0 +1,95 @@
+package thruk
+
+import (
+	"context"
+	"encoding/json"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// startPagingServer serves total hosts named host<n> and counts the
+// requests.
+func startPagingServer(total int, requests *int) (*Thruk, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		hosts := []HostStatus{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			hosts = append(hosts, HostStatus{Name: "host" + strconv.Itoa(i)})
+		}
+		json.NewEncoder(w).Encode(hosts)
+	}))
+	return NewThruk(server.URL, "site", "user", "pass", false), server.Close
+}
+
+func Test_Iterator(t *testing.T) {
+	t.Run("Objects are fetched page by page", func(t *testing.T) {
+		requests := 0
+		thruk, closeServer := startPagingServer(25, &requests)
+		defer closeServer()
+		thruk.PageSize = 10
+
+		it := thruk.Iterate(context.Background(), "hosts", nil)
+		var names []string
+		for it.Next() {
+			var host HostStatus
+			assert.NilError(t, it.Object(&host))
+			names = append(names, host.Name)
+		}
+		assert.NilError(t, it.Err())
+		assert.Equal(t, len(names), 25)
+		assert.Equal(t, names[24], "host24")
+		assert.Equal(t, requests, 3)
+	})
+	t.Run("Object fails outside of a page", func(t *testing.T) {
+		requests := 0
+		thruk, closeServer := startPagingServer(3, &requests)
+		defer closeServer()
+
+		it := thruk.Iterate(context.Background(), "hosts", nil)
+		var host HostStatus
+		assert.Equal(t, it.Object(&host), ErrorNoObject)
+		for it.Next() {
+		}
+		assert.NilError(t, it.Err())
+		assert.Equal(t, it.Object(&host), ErrorNoObject)
+	})
+	t.Run("Stopping early fetches no further pages", func(t *testing.T) {
+		requests := 0
+		thruk, closeServer := startPagingServer(100, &requests)
+		defer closeServer()
+		thruk.PageSize = 10
+
+		it := thruk.Iterate(context.Background(), "hosts", nil)
+		for i := 0; i < 5 && it.Next(); i++ {
+		}
+		assert.Equal(t, requests, 1)
+	})
+	t.Run("List methods read all pages", func(t *testing.T) {
+		requests := 0
+		thruk, closeServer := startPagingServer(20, &requests)
+		defer closeServer()
+		thruk.PageSize = 10
+
+		hosts, err := thruk.ListHostStatus(nil)
+		assert.NilError(t, err)
+		assert.Equal(t, len(hosts), 20)
+		assert.Equal(t, requests, 3)
+	})
+	t.Run("A limit in the query disables paging", func(t *testing.T) {
+		requests := 0
+		thruk, closeServer := startPagingServer(100, &requests)
+		defer closeServer()
+		thruk.PageSize = 10
+
+		hosts, err := thruk.ListHostStatus(url.Values{"limit": {"15"}})
+		assert.NilError(t, err)
+		assert.Equal(t, len(hosts), 15)
+		assert.Equal(t, requests, 1)
+	})
+}
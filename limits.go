@@ -0,0 +1,140 @@
+package thruk
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limits throttle the requests of a client. Reads are GET and HEAD
+// requests, everything else is a write, and each has its own budget. Zero
+// values leave a budget unlimited.
+type Limits struct {
+	// ReadRate and WriteRate are the sustained requests per second.
+	ReadRate  float64
+	WriteRate float64
+	// ReadBurst and WriteBurst are the requests allowed at once above the
+	// rate, 1 when not set.
+	ReadBurst  int
+	WriteBurst int
+	// MaxReadsInFlight and MaxWritesInFlight cap the requests waiting for a
+	// response at the same time.
+	MaxReadsInFlight  int
+	MaxWritesInFlight int
+}
+
+// WithLimits rate limits the requests of the client and caps how many run
+// concurrently. Requests wait for their turn until their context is done.
+// The limits are shared by all copies of the client.
+func WithLimits(limits Limits) Option {
+	return func(t *Thruk) {
+		t.limits = &requestLimits{
+			read:  newBudget(limits.ReadRate, limits.ReadBurst, limits.MaxReadsInFlight),
+			write: newBudget(limits.WriteRate, limits.WriteBurst, limits.MaxWritesInFlight),
+		}
+	}
+}
+
+type requestLimits struct {
+	read, write budget
+}
+
+type budget struct {
+	bucket *tokenBucket
+	slots  chan struct{}
+}
+
+func newBudget(rate float64, burst, inFlight int) budget {
+	var b budget
+	if rate > 0 {
+		if burst <= 0 {
+			burst = 1
+		}
+		b.bucket = &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+	}
+	if inFlight > 0 {
+		b.slots = make(chan struct{}, inFlight)
+	}
+	return b
+}
+
+// acquire waits for a token and a free slot. The returned func frees the
+// slot again.
+func (b budget) acquire(ctx context.Context) (func(), error) {
+	if err := b.bucket.wait(ctx); err != nil {
+		return nil, err
+	}
+	if b.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case b.slots <- struct{}{}:
+		return func() { <-b.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type tokenBucket struct {
+	sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// wait takes a token, waiting until one is refilled if the bucket is empty.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	for {
+		b.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// do performs req within the limits of the client. A slot is held until
+// the response headers arrived.
+func (t Thruk) do(req *http.Request) (*http.Response, error) {
+	return t.observe(req, func(req *http.Request) (*http.Response, error) {
+		return t.logged(req, t.limited)
+	})
+}
+
+func (t Thruk) limited(req *http.Request) (*http.Response, error) {
+	if t.limits == nil {
+		return t.client.Do(req)
+	}
+	b := t.limits.write
+	if req.Method == "GET" || req.Method == "HEAD" {
+		b = t.limits.read
+	}
+	release, err := b.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return t.client.Do(req)
+}
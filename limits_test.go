@@ -0,0 +1,97 @@
+package thruk
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Limits(t *testing.T) {
+	t.Run("Requests above the burst wait for the rate", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("[]"))
+		}))
+		defer server.Close()
+		thruk := NewThruk(server.URL, "site", "user", "pass", false, WithLimits(Limits{ReadRate: 20, ReadBurst: 2}))
+
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			var objects []interface{}
+			assert.NilError(t, thruk.getJSON("/site/thruk/r/hosts", &objects))
+		}
+		elapsed := time.Since(start)
+		assert.Assert(t, elapsed >= 90*time.Millisecond, elapsed)
+	})
+
+	t.Run("Reads and writes have separate budgets", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("{}"))
+		}))
+		defer server.Close()
+		thruk := NewThruk(server.URL, "site", "user", "pass", false, WithLimits(Limits{ReadRate: 0.001, WriteRate: 1000}))
+
+		_, err := thruk.send("GET", "/site/thruk/r/hosts", nil)
+		assert.NilError(t, err)
+		for i := 0; i < 3; i++ {
+			_, err := thruk.send("POST", "/site/thruk/r/config/save", nil)
+			assert.NilError(t, err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = thruk.sendContext(ctx, "GET", "/site/thruk/r/hosts", nil)
+		assert.Equal(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Requests in flight are capped", func(t *testing.T) {
+		var inFlight, maxInFlight int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := atomic.AddInt32(&inFlight, 1)
+			for {
+				seen := atomic.LoadInt32(&maxInFlight)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			w.Write([]byte("[]"))
+		}))
+		defer server.Close()
+		thruk := NewThruk(server.URL, "site", "user", "pass", false, WithLimits(Limits{MaxReadsInFlight: 2}))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var objects []interface{}
+				assert.Check(t, thruk.getJSON("/site/thruk/r/hosts", &objects))
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, atomic.LoadInt32(&maxInFlight), int32(2))
+	})
+
+	t.Run("Waiting for a slot stops with the context", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+		thruk := NewThruk(server.URL, "site", "user", "pass", false, WithLimits(Limits{MaxWritesInFlight: 1}))
+
+		go thruk.send("DELETE", "/site/thruk/r/config/objects/x", nil)
+		time.Sleep(20 * time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := thruk.sendContext(ctx, "POST", "/site/thruk/r/config/save", nil)
+		assert.Equal(t, err, context.DeadlineExceeded)
+	})
+}
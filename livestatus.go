@@ -0,0 +1,377 @@
+package thruk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrorInvalidLivestatusResponse = errors.New("[ERROR] invalid livestatus response")
+
+// LivestatusQuery builds an LQL GET query:
+//
+//	query := thruk.NewLivestatusQuery("services").
+//		Columns("host_name", "description", "state").
+//		Filter("state", ">", 0).
+//		Filter("acknowledged", "=", 0).
+//		And(2)
+type LivestatusQuery struct {
+	table   string
+	columns []string
+	headers []string
+	err     error
+}
+
+func NewLivestatusQuery(table string) *LivestatusQuery {
+	return &LivestatusQuery{table: table}
+}
+
+func (q *LivestatusQuery) Columns(columns ...string) *LivestatusQuery {
+	q.columns = append(q.columns, columns...)
+	return q
+}
+
+// Filter adds a filter like Filter("host_name", "~", "^web"). The
+// operators are Livestatus', e.g. =, ~, =~, ~~, <, > and >=, which tests
+// membership on list columns. A leading ! negates them.
+func (q *LivestatusQuery) Filter(column, operator string, value interface{}) *LivestatusQuery {
+	return q.header("Filter", column+" "+operator+" "+fmt.Sprint(value))
+}
+
+// And combines the last n filters, Or any of them and Negate inverts the
+// last one.
+func (q *LivestatusQuery) And(n int) *LivestatusQuery {
+	return q.header("And", strconv.Itoa(n))
+}
+
+func (q *LivestatusQuery) Or(n int) *LivestatusQuery {
+	return q.header("Or", strconv.Itoa(n))
+}
+
+func (q *LivestatusQuery) Negate() *LivestatusQuery {
+	return q.header("Negate", "")
+}
+
+// Stats counts the rows matching a condition, or aggregates a column when
+// operator is a function like "sum" or "avg" and value is empty. A query
+// with stats returns one row per group of its columns.
+func (q *LivestatusQuery) Stats(column, operator string, value interface{}) *LivestatusQuery {
+	if value == "" {
+		return q.header("Stats", operator+" "+column)
+	}
+	return q.header("Stats", column+" "+operator+" "+fmt.Sprint(value))
+}
+
+// StatsAnd, StatsOr and StatsNegate combine stats like And, Or and Negate
+// combine filters.
+func (q *LivestatusQuery) StatsAnd(n int) *LivestatusQuery {
+	return q.header("StatsAnd", strconv.Itoa(n))
+}
+
+func (q *LivestatusQuery) StatsOr(n int) *LivestatusQuery {
+	return q.header("StatsOr", strconv.Itoa(n))
+}
+
+func (q *LivestatusQuery) StatsNegate() *LivestatusQuery {
+	return q.header("StatsNegate", "")
+}
+
+func (q *LivestatusQuery) Limit(n int) *LivestatusQuery {
+	return q.header("Limit", strconv.Itoa(n))
+}
+
+func (q *LivestatusQuery) header(name, value string) *LivestatusQuery {
+	if strings.ContainsAny(value, "\r\n") && q.err == nil {
+		q.err = fmt.Errorf("invalid livestatus %s: %q", name, value)
+	}
+	q.headers = append(q.headers, strings.TrimSpace(name+": "+value))
+	return q
+}
+
+// String returns the query without the output headers the client adds.
+func (q *LivestatusQuery) String() string {
+	lines := []string{"GET " + q.table}
+	if len(q.columns) > 0 {
+		lines = append(lines, "Columns: "+strings.Join(q.columns, " "))
+	}
+	lines = append(lines, q.headers...)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Livestatus queries a Livestatus socket directly, which is faster than
+// the REST API for large status queries.
+type Livestatus struct {
+	// Network is "tcp" or "unix".
+	Network string
+	Address string
+	// Timeout limits connecting and every query, 30 seconds when not set.
+	Timeout time.Duration
+	// KeepAlive reuses the connection for following queries. Commands
+	// always use a connection of their own.
+	KeepAlive bool
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewLivestatus(network, address string) *Livestatus {
+	return &Livestatus{Network: network, Address: address, KeepAlive: true}
+}
+
+// Close closes a kept alive connection.
+func (l *Livestatus) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closeConn()
+}
+
+func (l *Livestatus) closeConn() error {
+	if l.conn == nil {
+		return nil
+	}
+	err := l.conn.Close()
+	l.conn, l.reader = nil, nil
+	return err
+}
+
+func (l *Livestatus) timeout() time.Duration {
+	if l.Timeout > 0 {
+		return l.Timeout
+	}
+	return 30 * time.Second
+}
+
+// Query runs query and decodes the rows into v, which must point to a
+// slice of structs or maps. Rows are decoded as JSON objects keyed by
+// column, stats columns are named stats_1, stats_2 and so on.
+func (l *Livestatus) Query(ctx context.Context, query *LivestatusQuery, v interface{}) error {
+	if query.err != nil {
+		return query.err
+	}
+	request := query.String() + "OutputFormat: json\nColumnHeaders: on\nResponseHeader: fixed16\n"
+	body, err := l.roundTrip(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]json.RawMessage
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return ErrorInvalidLivestatusResponse
+	}
+	columns := make([]string, len(rows[0]))
+	for i, column := range rows[0] {
+		if err := json.Unmarshal(column, &columns[i]); err != nil {
+			return ErrorInvalidLivestatusResponse
+		}
+	}
+	objects := make([]json.RawMessage, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		if len(row) != len(columns) {
+			return ErrorInvalidLivestatusResponse
+		}
+		object := make(map[string]json.RawMessage, len(columns))
+		for i, column := range columns {
+			object[column] = row[i]
+		}
+		encoded, err := json.Marshal(object)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, encoded)
+	}
+	return decodeRawList(objects, v)
+}
+
+// Command sends an external command like
+// Command(ctx, "ACKNOWLEDGE_HOST_PROBLEM", "web01", "1", "1", "1", "admin", "on it").
+// Livestatus does not answer commands, so errors of the command itself
+// are not reported. A write to a kept alive connection the server already
+// closed can succeed all the same, so every command is sent on a new
+// connection without KeepAlive.
+func (l *Livestatus) Command(ctx context.Context, name string, args ...string) error {
+	line := fmt.Sprintf("COMMAND [%d] %s", time.Now().Unix(), strings.Join(append([]string{name}, args...), ";"))
+	if strings.ContainsAny(line, "\r\n") {
+		return ErrorInvalidInput
+	}
+	conn, err := l.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	defer l.watch(ctx, conn)()
+	if _, err := io.WriteString(conn, line+"\n\n"); err != nil {
+		return l.contextError(ctx, err)
+	}
+	return nil
+}
+
+var hostStatusColumns = []string{
+	"name", "display_name", "address", "state", "state_type", "has_been_checked",
+	"current_attempt", "max_check_attempts", "acknowledged", "scheduled_downtime_depth",
+	"is_flapping", "latency", "execution_time", "plugin_output", "perf_data",
+	"last_check", "last_state_change", "groups",
+}
+
+var serviceStatusColumns = []string{
+	"host_name", "description", "display_name", "state", "state_type", "has_been_checked",
+	"current_attempt", "max_check_attempts", "acknowledged", "scheduled_downtime_depth",
+	"is_flapping", "latency", "execution_time", "plugin_output", "perf_data",
+	"last_check", "last_state_change", "groups", "host_groups",
+}
+
+// ListHostStatus returns the hosts matching the filters of query, all
+// hosts when it is nil. The query must be on the hosts table; without
+// columns it gets those of HostStatus. PeerKey and PeerName stay empty.
+func (l *Livestatus) ListHostStatus(ctx context.Context, query *LivestatusQuery) ([]HostStatus, error) {
+	query, err := statusQuery(query, "hosts", hostStatusColumns)
+	if err != nil {
+		return nil, err
+	}
+	var hosts []HostStatus
+	if err := l.Query(ctx, query, &hosts); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
+func (l *Livestatus) ListServiceStatus(ctx context.Context, query *LivestatusQuery) ([]ServiceStatus, error) {
+	query, err := statusQuery(query, "services", serviceStatusColumns)
+	if err != nil {
+		return nil, err
+	}
+	var services []ServiceStatus
+	if err := l.Query(ctx, query, &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+func statusQuery(query *LivestatusQuery, table string, columns []string) (*LivestatusQuery, error) {
+	if query == nil {
+		query = NewLivestatusQuery(table)
+	}
+	if query.table != table {
+		return nil, ErrorInvalidInput
+	}
+	if len(query.columns) == 0 {
+		withColumns := *query
+		withColumns.columns = columns
+		query = &withColumns
+	}
+	return query, nil
+}
+
+// roundTrip sends request and reads the fixed16 response. A kept alive
+// connection that fails before anything was read is replaced by a new one
+// once, as the server may have closed it.
+func (l *Livestatus) roundTrip(ctx context.Context, request string) ([]byte, error) {
+	if l.KeepAlive {
+		request += "KeepAlive: on\n"
+	}
+	request += "\n"
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for attempt := 0; ; attempt++ {
+		reused := l.conn != nil
+		body, started, err := l.exchange(ctx, request)
+		if err == nil {
+			if !l.KeepAlive {
+				l.closeConn()
+			}
+			return body, nil
+		}
+		l.closeConn()
+		if !reused || started || attempt > 0 || ctx.Err() != nil {
+			return nil, err
+		}
+	}
+}
+
+// exchange performs a single request and reports whether any of the
+// response was read.
+func (l *Livestatus) exchange(ctx context.Context, request string) ([]byte, bool, error) {
+	if l.conn == nil {
+		conn, err := l.dial(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		l.conn, l.reader = conn, bufio.NewReader(conn)
+	}
+	defer l.watch(ctx, l.conn)()
+
+	if _, err := io.WriteString(l.conn, request); err != nil {
+		return nil, false, l.contextError(ctx, err)
+	}
+
+	header := make([]byte, 16)
+	n, err := io.ReadFull(l.reader, header)
+	if err != nil {
+		return nil, n > 0, l.contextError(ctx, err)
+	}
+	status, err := strconv.Atoi(string(header[:3]))
+	if err != nil || header[3] != ' ' || header[15] != '\n' {
+		return nil, true, ErrorInvalidLivestatusResponse
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(string(header[4:15])))
+	if err != nil || length < 0 {
+		return nil, true, ErrorInvalidLivestatusResponse
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(l.reader, body); err != nil {
+		return nil, true, l.contextError(ctx, err)
+	}
+	if status != 200 {
+		return nil, true, fmt.Errorf("livestatus error %d: %s", status, strings.TrimSpace(string(body)))
+	}
+	return body, true, nil
+}
+
+func (l *Livestatus) dial(ctx context.Context) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: l.timeout()}
+	return dialer.DialContext(ctx, l.Network, l.Address)
+}
+
+// watch sets the timeout on conn and interrupts it when ctx is done,
+// until the returned func is called.
+func (l *Livestatus) watch(ctx context.Context, conn net.Conn) func() {
+	deadline := time.Now().Add(l.timeout())
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// contextError returns the error of ctx for connections it interrupted.
+// The deadline of ctx is also set on the connection, which may run out
+// before ctx reports it.
+func (l *Livestatus) contextError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
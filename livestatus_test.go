@@ -0,0 +1,337 @@
+package thruk
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeLivestatus struct {
+	sync.Mutex
+	listener    net.Listener
+	requests    []string
+	connections int
+	conns       []net.Conn
+	respond     func(request string) (int, string)
+}
+
+// startFakeLivestatus serves Livestatus requests with respond until the
+// returned func is called.
+func startFakeLivestatus(t *testing.T, network string, respond func(request string) (int, string)) (*fakeLivestatus, func()) {
+	address := "127.0.0.1:0"
+	dir := ""
+	if network == "unix" {
+		var err error
+		dir, err = ioutil.TempDir("", "livestatus")
+		assert.NilError(t, err)
+		address = filepath.Join(dir, "live")
+	}
+	listener, err := net.Listen(network, address)
+	assert.NilError(t, err)
+	fake := &fakeLivestatus{listener: listener, respond: respond}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			fake.Lock()
+			fake.connections++
+			fake.conns = append(fake.conns, conn)
+			fake.Unlock()
+			go fake.serve(conn)
+		}
+	}()
+	return fake, func() {
+		listener.Close()
+		if dir != "" {
+			os.RemoveAll(dir)
+		}
+	}
+}
+
+func (f *fakeLivestatus) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		var lines []string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if line == "\n" {
+				break
+			}
+			lines = append(lines, line)
+		}
+		request := strings.Join(lines, "")
+		f.Lock()
+		f.requests = append(f.requests, request)
+		f.Unlock()
+		if strings.HasPrefix(request, "GET ") {
+			status, body := f.respond(request)
+			fmt.Fprintf(conn, "%03d %11d\n%s", status, len(body), body)
+		}
+		if !strings.Contains(request, "KeepAlive: on\n") {
+			return
+		}
+	}
+}
+
+func (f *fakeLivestatus) Connections() int {
+	f.Lock()
+	defer f.Unlock()
+	return f.connections
+}
+
+// CloseConnections closes the connections of the server, like Livestatus
+// does with idle ones.
+func (f *fakeLivestatus) CloseConnections() {
+	f.Lock()
+	defer f.Unlock()
+	for _, conn := range f.conns {
+		conn.Close()
+	}
+	f.conns = nil
+}
+
+func (f *fakeLivestatus) Requests() []string {
+	f.Lock()
+	defer f.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func Test_LivestatusQuery(t *testing.T) {
+	t.Run("Queries are written as LQL", func(t *testing.T) {
+		query := NewLivestatusQuery("services").
+			Columns("host_name", "description").
+			Filter("state", ">", 0).
+			Filter("host_groups", ">=", "web").
+			Or(2).
+			Negate().
+			Stats("state", "=", 2).
+			Stats("latency", "avg", "").
+			StatsNegate().
+			Limit(10)
+		assert.Equal(t, query.String(), "GET services\n"+
+			"Columns: host_name description\n"+
+			"Filter: state > 0\n"+
+			"Filter: host_groups >= web\n"+
+			"Or: 2\n"+
+			"Negate:\n"+
+			"Stats: state = 2\n"+
+			"Stats: avg latency\n"+
+			"StatsNegate:\n"+
+			"Limit: 10\n")
+	})
+
+	t.Run("Values with line breaks are rejected", func(t *testing.T) {
+		live := NewLivestatus("tcp", "127.0.0.1:1")
+		var rows []map[string]interface{}
+		err := live.Query(context.Background(), NewLivestatusQuery("hosts").Filter("name", "=", "web\nCOMMAND"), &rows)
+		assert.ErrorContains(t, err, "invalid livestatus Filter")
+	})
+}
+
+func Test_Livestatus(t *testing.T) {
+	hostsResponse := `[["name","state","groups","latency"],["web01",0,["web","linux"],0.25],["db01",1,[],0.5]]`
+
+	for _, network := range []string{"tcp", "unix"} {
+		t.Run("Hosts are returned as HostStatus over "+network, func(t *testing.T) {
+			fake, stop := startFakeLivestatus(t, network, func(string) (int, string) {
+				return 200, hostsResponse
+			})
+			defer stop()
+			live := NewLivestatus(network, fake.listener.Addr().String())
+			defer live.Close()
+
+			hosts, err := live.ListHostStatus(context.Background(), NewLivestatusQuery("hosts").Filter("state", "!=", 2))
+			assert.NilError(t, err)
+			assert.DeepEqual(t, hosts, []HostStatus{
+				{Name: "web01", State: 0, Groups: []string{"web", "linux"}, Latency: 0.25},
+				{Name: "db01", State: 1, Groups: []string{}, Latency: 0.5},
+			})
+			request := fake.Requests()[0]
+			assert.Assert(t, strings.HasPrefix(request, "GET hosts\nColumns: name display_name address state "), request)
+			for _, header := range []string{"Filter: state != 2\n", "OutputFormat: json\n", "ColumnHeaders: on\n", "ResponseHeader: fixed16\n", "KeepAlive: on\n"} {
+				assert.Assert(t, strings.Contains(request, header), header)
+			}
+		})
+	}
+
+	t.Run("Services are returned as ServiceStatus", func(t *testing.T) {
+		fake, stop := startFakeLivestatus(t, "tcp", func(string) (int, string) {
+			return 200, `[["host_name","description","state","host_groups"],["web01","http",2,["web"]]]`
+		})
+		defer stop()
+		live := NewLivestatus("tcp", fake.listener.Addr().String())
+		defer live.Close()
+
+		services, err := live.ListServiceStatus(context.Background(), nil)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, services, []ServiceStatus{{HostName: "web01", Description: "http", State: 2, HostGroups: []string{"web"}}})
+
+		_, err = live.ListServiceStatus(context.Background(), NewLivestatusQuery("hosts"))
+		assert.Equal(t, err, ErrorInvalidInput)
+	})
+
+	t.Run("Stats are decoded by column name", func(t *testing.T) {
+		fake, stop := startFakeLivestatus(t, "tcp", func(string) (int, string) {
+			return 200, `[["state","stats_1"],[0,12],[2,3]]`
+		})
+		defer stop()
+		live := NewLivestatus("tcp", fake.listener.Addr().String())
+		defer live.Close()
+
+		var counts []struct {
+			State int `json:"state"`
+			Count int `json:"stats_1"`
+		}
+		err := live.Query(context.Background(), NewLivestatusQuery("services").Columns("state").Stats("state", ">=", 0), &counts)
+		assert.NilError(t, err)
+		assert.Equal(t, len(counts), 2)
+		assert.Equal(t, counts[1].State, 2)
+		assert.Equal(t, counts[1].Count, 3)
+	})
+
+	t.Run("Kept alive connections are reused", func(t *testing.T) {
+		fake, stop := startFakeLivestatus(t, "tcp", func(string) (int, string) {
+			return 200, hostsResponse
+		})
+		defer stop()
+		live := NewLivestatus("tcp", fake.listener.Addr().String())
+		defer live.Close()
+
+		for i := 0; i < 3; i++ {
+			_, err := live.ListHostStatus(context.Background(), nil)
+			assert.NilError(t, err)
+		}
+		_, err := live.ListHostStatus(context.Background(), nil)
+		assert.NilError(t, err)
+		assert.Equal(t, fake.Connections(), 1)
+		assert.Equal(t, len(fake.Requests()), 4)
+	})
+
+	t.Run("Commands are sent on a connection of their own", func(t *testing.T) {
+		fake, stop := startFakeLivestatus(t, "tcp", func(string) (int, string) {
+			return 200, hostsResponse
+		})
+		defer stop()
+		live := NewLivestatus("tcp", fake.listener.Addr().String())
+		defer live.Close()
+
+		_, err := live.ListHostStatus(context.Background(), nil)
+		assert.NilError(t, err)
+		// the server closes the idle kept alive connection
+		fake.CloseConnections()
+		assert.NilError(t, live.Command(context.Background(), "DISABLE_NOTIFICATIONS"))
+		for len(fake.Requests()) < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Assert(t, strings.HasPrefix(fake.Requests()[1], "COMMAND ["))
+		assert.Assert(t, !strings.Contains(fake.Requests()[1], "KeepAlive"))
+		assert.Equal(t, fake.Connections(), 2)
+
+		_, err = live.ListHostStatus(context.Background(), nil)
+		assert.NilError(t, err)
+		assert.Equal(t, fake.Connections(), 3)
+	})
+
+	t.Run("Without keepalive every query connects", func(t *testing.T) {
+		fake, stop := startFakeLivestatus(t, "tcp", func(string) (int, string) {
+			return 200, hostsResponse
+		})
+		defer stop()
+		live := NewLivestatus("tcp", fake.listener.Addr().String())
+		live.KeepAlive = false
+
+		for i := 0; i < 2; i++ {
+			_, err := live.ListHostStatus(context.Background(), nil)
+			assert.NilError(t, err)
+		}
+		assert.Equal(t, fake.Connections(), 2)
+		assert.Assert(t, !strings.Contains(fake.Requests()[0], "KeepAlive"))
+	})
+
+	t.Run("Closed kept alive connections are replaced", func(t *testing.T) {
+		fake, stop := startFakeLivestatus(t, "tcp", func(string) (int, string) {
+			return 200, hostsResponse
+		})
+		defer stop()
+		live := NewLivestatus("tcp", fake.listener.Addr().String())
+		defer live.Close()
+
+		_, err := live.ListHostStatus(context.Background(), nil)
+		assert.NilError(t, err)
+		// the server closes idle connections
+		live.mu.Lock()
+		live.conn.(*net.TCPConn).CloseRead()
+		live.mu.Unlock()
+		_, err = live.ListHostStatus(context.Background(), nil)
+		assert.NilError(t, err)
+		assert.Equal(t, fake.Connections(), 2)
+	})
+
+	t.Run("Error statuses are returned with their message", func(t *testing.T) {
+		fake, stop := startFakeLivestatus(t, "tcp", func(string) (int, string) {
+			return 400, "Invalid GET request, no such table 'hots'\n"
+		})
+		defer stop()
+		live := NewLivestatus("tcp", fake.listener.Addr().String())
+		defer live.Close()
+
+		var rows []map[string]interface{}
+		err := live.Query(context.Background(), NewLivestatusQuery("hots"), &rows)
+		assert.Error(t, err, "livestatus error 400: Invalid GET request, no such table 'hots'")
+	})
+
+	t.Run("Commands are sent with a timestamp", func(t *testing.T) {
+		fake, stop := startFakeLivestatus(t, "tcp", nil)
+		defer stop()
+		live := NewLivestatus("tcp", fake.listener.Addr().String())
+		live.KeepAlive = false
+
+		before := time.Now().Unix()
+		err := live.Command(context.Background(), "ACKNOWLEDGE_HOST_PROBLEM", "web01", "1", "1", "1", "admin", "on it")
+		assert.NilError(t, err)
+		assert.Equal(t, live.Command(context.Background(), "ADD_HOST_COMMENT", "web01", "x\nCOMMAND [0] SHUTDOWN_PROGRAM"), ErrorInvalidInput)
+		for len(fake.Requests()) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		request := fake.Requests()[0]
+		assert.Assert(t, strings.HasPrefix(request, "COMMAND ["), request)
+		end := strings.Index(request, "] ")
+		timestamp, err := strconv.ParseInt(request[len("COMMAND ["):end], 10, 64)
+		assert.NilError(t, err)
+		assert.Assert(t, timestamp >= before)
+		assert.Equal(t, request[end+2:], "ACKNOWLEDGE_HOST_PROBLEM;web01;1;1;1;admin;on it\n")
+	})
+
+	t.Run("Queries stop with the context", func(t *testing.T) {
+		block := make(chan struct{})
+		fake, stop := startFakeLivestatus(t, "tcp", func(string) (int, string) {
+			<-block
+			return 200, hostsResponse
+		})
+		defer stop()
+		defer close(block)
+		live := NewLivestatus("tcp", fake.listener.Addr().String())
+		defer live.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := live.ListHostStatus(ctx, nil)
+		assert.Equal(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,220 @@
+package thruk
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Logger receives structured log records, args are alternating keys and
+// values. A *slog.Logger can be used as is.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// maxLoggedBody is the number of body bytes logged, longer bodies are
+// truncated.
+const maxLoggedBody = 2048
+
+const redacted = "[REDACTED]"
+
+// WithLogger logs every request at debug level with its method, URL,
+// duration, status and truncated bodies, and failed requests at error
+// level. Credentials are always redacted: the Authorization header, API
+// keys and password-like attributes or custom variables in URLs and
+// bodies.
+func WithLogger(logger Logger) Option {
+	return func(t *Thruk) {
+		t.logger = logger
+	}
+}
+
+// sensitiveName matches names of attributes, custom variables, query
+// parameters and headers holding credentials. Words are matched whole, so
+// passive_checks_enabled or author are not redacted, apart from upper case
+// custom variables ending in PASS or PASSWORD like _DBPASS.
+var sensitiveName = regexp.MustCompile(`(?i:(^|[_-])(pass|passwd|password|secret|token|api[_-]?key|auth[_-]?key|community|credential|authorization|cookie)s?($|[_-]))|[A-Z]PASS(WORD)?$`)
+
+// redactJSON rewrites a JSON document, or the start of a truncated one,
+// with the values of sensitive attributes replaced, including arrays and
+// objects. ok is false when body is not a JSON object or array.
+func redactJSON(body []byte) (string, bool) {
+	if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return "", false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var out bytes.Buffer
+	// counts holds the number of keys and values written to each open
+	// object or array, objects is set for objects
+	var counts []int
+	var objects []bool
+	redactNext := false
+	skip := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			// a truncated body ends with an error, what was read is kept
+			return out.String(), true
+		}
+		delim, isDelim := token.(json.Delim)
+		opens := isDelim && (delim == '{' || delim == '[')
+		if skip > 0 {
+			if opens {
+				skip++
+			} else if isDelim {
+				skip--
+			}
+			continue
+		}
+		if isDelim && !opens {
+			counts, objects = counts[:len(counts)-1], objects[:len(objects)-1]
+			out.WriteString(delim.String())
+			continue
+		}
+
+		if level := len(counts) - 1; level >= 0 {
+			isKey := objects[level] && counts[level]%2 == 0
+			if counts[level] > 0 && (isKey || !objects[level]) {
+				out.WriteByte(',')
+			}
+			counts[level]++
+			if isKey {
+				name, _ := token.(string)
+				writeJSONValue(&out, name)
+				out.WriteByte(':')
+				redactNext = sensitiveName.MatchString(name)
+				continue
+			}
+		}
+		if redactNext {
+			redactNext = false
+			writeJSONValue(&out, redacted)
+			if opens {
+				skip = 1
+			}
+			continue
+		}
+		if opens {
+			counts, objects = append(counts, 0), append(objects, delim == '{')
+			out.WriteString(delim.String())
+			continue
+		}
+		writeJSONValue(&out, token)
+	}
+}
+
+func writeJSONValue(out *bytes.Buffer, value interface{}) {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+	// Encode ends every value with a newline
+	out.Truncate(out.Len() - 1)
+}
+
+func redactURL(u *url.URL) string {
+	redactedURL := *u
+	if u.User != nil {
+		redactedURL.User = url.User(redacted)
+	}
+	query := u.Query()
+	for name := range query {
+		if sensitiveName.MatchString(name) {
+			query[name] = []string{redacted}
+		}
+	}
+	redactedURL.RawQuery = query.Encode()
+	return redactedURL.String()
+}
+
+func redactHeader(header http.Header) map[string]string {
+	redactedHeader := map[string]string{}
+	for name := range header {
+		value := header.Get(name)
+		if sensitiveName.MatchString(name) {
+			value = redacted
+		}
+		redactedHeader[name] = value
+	}
+	return redactedHeader
+}
+
+// loggedBody returns the start of a body for the log. Bodies that are not
+// text, like report downloads, are only described.
+func loggedBody(contentType string, body []byte, truncated bool) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if contentType != "" && !strings.Contains(contentType, "json") && !strings.HasPrefix(contentType, "text/") {
+		return "[" + contentType + "]"
+	}
+	text, ok := redactJSON(body)
+	if !ok {
+		text = string(body)
+	}
+	if truncated {
+		text += "...[truncated]"
+	}
+	return text
+}
+
+// peekBody reads the start of body and returns it with a reader that
+// still yields the whole body.
+func peekBody(body io.ReadCloser) ([]byte, bool, io.ReadCloser, error) {
+	start, err := ioutil.ReadAll(io.LimitReader(body, maxLoggedBody+1))
+	if err != nil {
+		return nil, false, body, err
+	}
+	truncated := len(start) > maxLoggedBody
+	rest := struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(start), body), body}
+	if truncated {
+		start = start[:maxLoggedBody]
+	}
+	return start, truncated, rest, nil
+}
+
+// logged performs req with send and logs it.
+func (t Thruk) logged(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if t.logger == nil {
+		return send(req)
+	}
+	args := []interface{}{"method", req.Method, "url", redactURL(req.URL), "header", redactHeader(req.Header)}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			start, truncated, _, _ := peekBody(body)
+			body.Close()
+			args = append(args, "request_body", loggedBody(req.Header.Get("Content-Type"), start, truncated))
+		}
+	}
+
+	start := time.Now()
+	resp, err := send(req)
+	args = append(args, "duration", time.Since(start))
+	if err != nil {
+		t.logger.Error("thruk request failed", append(args, "error", err.Error())...)
+		return resp, err
+	}
+
+	args = append(args, "status", resp.StatusCode)
+	body, truncated, rest, peekErr := peekBody(resp.Body)
+	resp.Body = rest
+	if peekErr == nil {
+		args = append(args, "response_body", loggedBody(resp.Header.Get("Content-Type"), body, truncated))
+	}
+	if resp.StatusCode >= 400 {
+		t.logger.Error("thruk request failed", append(args, "error", resp.Status)...)
+	} else {
+		t.logger.Debug("thruk request", args...)
+	}
+	return resp, err
+}
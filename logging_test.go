@@ -0,0 +1,158 @@
+package thruk
+
+import (
+	"fmt"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type logRecord struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+type recordingLogger struct {
+	records []logRecord
+}
+
+func (l *recordingLogger) record(level, msg string, args []interface{}) {
+	fields := map[string]interface{}{}
+	for i := 0; i+1 < len(args); i += 2 {
+		fields[args[i].(string)] = args[i+1]
+	}
+	l.records = append(l.records, logRecord{level, msg, fields})
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) {
+	l.record("debug", msg, args)
+}
+
+func (l *recordingLogger) Error(msg string, args ...interface{}) {
+	l.record("error", msg, args)
+}
+
+func Test_Logger(t *testing.T) {
+	t.Run("Requests are logged without credentials", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"message":"ok","objects":[{":ID":"h1","_SNMP_COMMUNITY":"public"}]}`))
+		}))
+		defer server.Close()
+		logger := &recordingLogger{}
+		thruk := NewThruk(server.URL, "site", "admin", "hunter2", false, WithLogger(logger))
+
+		resp, err := thruk.PostURL("/site/thruk/r/config/objects/", strings.NewReader(`{":TYPE":"host","_DB_PASSWORD":"s3cret","_PORT":"5432"}`))
+		assert.NilError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, len(logger.records), 1)
+		record := logger.records[0]
+		assert.Equal(t, record.level, "debug")
+		assert.Equal(t, record.fields["method"], "POST")
+		assert.Equal(t, record.fields["url"], server.URL+"/site/thruk/r/config/objects/")
+		assert.Equal(t, record.fields["status"], 200)
+		assert.Equal(t, record.fields["header"].(map[string]string)["Authorization"], "[REDACTED]")
+		assert.Assert(t, strings.Contains(record.fields["request_body"].(string), `"_DB_PASSWORD":"[REDACTED]"`), record.fields["request_body"])
+		assert.Assert(t, strings.Contains(record.fields["request_body"].(string), `"_PORT":"5432"`))
+		assert.Equal(t, record.fields["response_body"], `{"message":"ok","objects":[{":ID":"h1","_SNMP_COMMUNITY":"[REDACTED]"}]}`)
+		all := fmt.Sprint(logger.records)
+		for _, secret := range []string{"hunter2", "s3cret", "public"} {
+			assert.Assert(t, !strings.Contains(all, secret), secret)
+		}
+	})
+
+	t.Run("Failed requests are logged as errors and keep their body", func(t *testing.T) {
+		long := strings.Repeat("x", 3*maxLoggedBody)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(long))
+		}))
+		defer server.Close()
+		logger := &recordingLogger{}
+		thruk := NewThruk(server.URL, "site", "admin", "hunter2", false, WithLogger(logger))
+
+		resp, err := thruk.GetURL("/site/thruk/r/hosts?token=abc&name=web01")
+		assert.NilError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NilError(t, err)
+		assert.Equal(t, string(body), long)
+
+		record := logger.records[0]
+		assert.Equal(t, record.level, "error")
+		assert.Equal(t, record.fields["error"], "500 Internal Server Error")
+		assert.Equal(t, record.fields["url"], server.URL+"/site/thruk/r/hosts?name=web01&token=%5BREDACTED%5D")
+		assert.Equal(t, record.fields["response_body"], long[:maxLoggedBody]+"...[truncated]")
+	})
+
+	t.Run("Unreachable servers are errors instead of exits", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		logger := &recordingLogger{}
+		thruk := NewThruk(server.URL, "site", "admin", "hunter2", false, WithLogger(logger))
+
+		_, err := thruk.GetURL("/site/thruk/r/hosts")
+		assert.Assert(t, err != nil)
+		_, err = thruk.PostURL("/site/thruk/r/config/save", nil)
+		assert.Assert(t, err != nil)
+		assert.Assert(t, thruk.DeleteURL("/site/thruk/r/config/objects/h1") != nil)
+		assert.Equal(t, len(logger.records), 3)
+		assert.Equal(t, logger.records[1].level, "error")
+		assert.Assert(t, logger.records[1].fields["error"] != nil)
+	})
+}
+
+func Test_redactJSON(t *testing.T) {
+	for body, expected := range map[string]string{
+		`{"password":"a\"b","name":"x"}`:      `{"password":"[REDACTED]","name":"x"}`,
+		`{"_API_KEY": 1234, "port": 22}`:      `{"_API_KEY":"[REDACTED]","port":22}`,
+		`[{"_PASS":"x"},{"_TOKEN":"abc`:       `[{"_PASS":"[REDACTED]"},{"_TOKEN":`,
+		`{"notes":"password: see vault"}`:     `{"notes":"password: see vault"}`,
+		`{"options":["d","u"],"secret":"s"}`:  `{"options":["d","u"],"secret":"[REDACTED]"}`,
+		`{"password":["x","y"],"name":"x"}`:   `{"password":"[REDACTED]","name":"x"}`,
+		`{"token":{"a":["b",{"c":1}]},"n":1}`: `{"token":"[REDACTED]","n":1}`,
+		`[{"_PASS":["x"]},{"_TOKEN":{"a":"b`:  `[{"_PASS":"[REDACTED]"},{"_TOKEN":"[REDACTED]"`,
+		`{"passive_checks_enabled":"1","author":"admin","_DBPASS":"x","_SNMP_COMMUNITY":"public"}`: `{"passive_checks_enabled":"1","author":"admin","_DBPASS":"[REDACTED]","_SNMP_COMMUNITY":"[REDACTED]"}`,
+	} {
+		t.Run(body, func(t *testing.T) {
+			text, ok := redactJSON([]byte(body))
+			assert.Assert(t, ok)
+			assert.Equal(t, text, expected)
+		})
+	}
+	t.Run("Text that is not a JSON object or array is not rewritten", func(t *testing.T) {
+		for _, body := range []string{"", "404 page not found", "password=x"} {
+			_, ok := redactJSON([]byte(body))
+			assert.Assert(t, !ok, body)
+		}
+	})
+}
+
+func Test_sensitiveName(t *testing.T) {
+	for name, sensitive := range map[string]bool{
+		"password":               true,
+		"_DB_PASSWORD":           true,
+		"_DBPASS":                true,
+		"api_key":                true,
+		"apikey":                 true,
+		"X-Thruk-Auth-Key":       true,
+		"Authorization":          true,
+		"Cookie":                 true,
+		"tokens":                 true,
+		"passive_checks_enabled": false,
+		"author":                 false,
+		"compass":                false,
+		"host_name":              false,
+		"notes_url":              false,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, sensitiveName.MatchString(name), sensitive)
+		})
+	}
+}
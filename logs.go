@@ -0,0 +1,151 @@
+package thruk
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogEntry is a line of the core's event log as returned by /logs.
+type LogEntry struct {
+	Time               int64  `json:"time"`
+	Class              int    `json:"class"`
+	Type               string `json:"type"`
+	Message            string `json:"message"`
+	HostName           string `json:"host_name,omitempty"`
+	ServiceDescription string `json:"service_description,omitempty"`
+	State              int    `json:"state"`
+	StateType          string `json:"state_type,omitempty"`
+	PluginOutput       string `json:"plugin_output,omitempty"`
+	ContactName        string `json:"contact_name,omitempty"`
+	CommandName        string `json:"command_name,omitempty"`
+	Options            string `json:"options,omitempty"`
+	PeerKey            string `json:"peer_key,omitempty"`
+}
+
+type LogKind int
+
+const (
+	LogOther LogKind = iota
+	// LogAlert is a host or service state change.
+	LogAlert
+	LogNotification
+	// LogState is the state of an object logged at startup or log rotation.
+	LogState
+	LogExternalCommand
+	LogDowntime
+	LogFlapping
+)
+
+var logKindTypes = map[LogKind][]string{
+	LogAlert:           {"HOST ALERT", "SERVICE ALERT"},
+	LogNotification:    {"HOST NOTIFICATION", "SERVICE NOTIFICATION"},
+	LogState:           {"CURRENT HOST STATE", "CURRENT SERVICE STATE", "INITIAL HOST STATE", "INITIAL SERVICE STATE"},
+	LogExternalCommand: {"EXTERNAL COMMAND"},
+	LogDowntime:        {"HOST DOWNTIME ALERT", "SERVICE DOWNTIME ALERT"},
+	LogFlapping:        {"HOST FLAPPING ALERT", "SERVICE FLAPPING ALERT"},
+}
+
+func (e LogEntry) Kind() LogKind {
+	for kind, types := range logKindTypes {
+		if contains(types, e.Type) {
+			return kind
+		}
+	}
+	return LogOther
+}
+
+func (e LogEntry) Timestamp() time.Time {
+	return time.Unix(e.Time, 0)
+}
+
+// LogFilter narrows down log queries. Without Start, Thruk only returns the
+// last 24 hours.
+type LogFilter struct {
+	Start              time.Time
+	End                time.Time
+	HostName           string
+	ServiceDescription string
+	ContactName        string
+	// Kinds restricts the entries to the given kinds, all when empty.
+	Kinds []LogKind
+}
+
+func (f LogFilter) query() url.Values {
+	query := url.Values{"sort": {"time"}}
+	if !f.Start.IsZero() {
+		query.Set("time[gte]", strconv.FormatInt(f.Start.Unix(), 10))
+	}
+	if !f.End.IsZero() {
+		query.Set("time[lt]", strconv.FormatInt(f.End.Unix(), 10))
+	}
+	if f.HostName != "" {
+		query.Set("host_name", f.HostName)
+	}
+	if f.ServiceDescription != "" {
+		query.Set("service_description", f.ServiceDescription)
+	}
+	if f.ContactName != "" {
+		query.Set("contact_name", f.ContactName)
+	}
+	var types []string
+	for _, kind := range f.Kinds {
+		types = append(types, logKindTypes[kind]...)
+	}
+	if len(types) > 0 {
+		query.Set("type[regex]", "^("+strings.Join(types, "|")+")$")
+	}
+	return query
+}
+
+// LogIterator pages through log entries, so long time ranges never have to
+// be held in memory at once.
+type LogIterator struct {
+	objects *Iterator
+	entry   LogEntry
+	err     error
+}
+
+// Next advances to the next entry and reports whether there is one.
+func (it *LogIterator) Next() bool {
+	if it.err != nil || !it.objects.Next() {
+		return false
+	}
+	it.entry = LogEntry{}
+	it.err = it.objects.Object(&it.entry)
+	return it.err == nil
+}
+
+// Entry returns the entry Next advanced to.
+func (it *LogIterator) Entry() LogEntry {
+	return it.entry
+}
+
+func (it *LogIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.objects.Err()
+}
+
+// IterateLogs streams the log entries matching filter in chronological
+// order.
+func (t Thruk) IterateLogs(ctx context.Context, filter LogFilter) *LogIterator {
+	return &LogIterator{objects: t.Iterate(ctx, "logs", filter.query())}
+}
+
+// IterateAlerts streams host and service alerts from /alerts.
+func (t Thruk) IterateAlerts(ctx context.Context, filter LogFilter) *LogIterator {
+	return &LogIterator{objects: t.Iterate(ctx, "alerts", filter.query())}
+}
+
+func (t Thruk) ListLogs(filter LogFilter) ([]LogEntry, error) {
+	var entries []LogEntry
+	it := t.IterateLogs(context.Background(), filter)
+	for it.Next() {
+		entries = append(entries, it.Entry())
+	}
+	return entries, it.Err()
+}
@@ -0,0 +1,59 @@
+package thruk
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+func Test_LogEntry_Kind(t *testing.T) {
+	t.Run("Types map to kinds", func(t *testing.T) {
+		assert.Equal(t, LogEntry{Type: "SERVICE ALERT"}.Kind(), LogAlert)
+		assert.Equal(t, LogEntry{Type: "HOST NOTIFICATION"}.Kind(), LogNotification)
+		assert.Equal(t, LogEntry{Type: "INITIAL HOST STATE"}.Kind(), LogState)
+		assert.Equal(t, LogEntry{Type: "EXTERNAL COMMAND"}.Kind(), LogExternalCommand)
+		assert.Equal(t, LogEntry{Type: "SERVICE DOWNTIME ALERT"}.Kind(), LogDowntime)
+		assert.Equal(t, LogEntry{Type: "HOST FLAPPING ALERT"}.Kind(), LogFlapping)
+		assert.Equal(t, LogEntry{Type: "LOG ROTATION"}.Kind(), LogOther)
+	})
+}
+
+func Test_LogFilter_query(t *testing.T) {
+	t.Run("Empty filter only sorts by time", func(t *testing.T) {
+		assert.Equal(t, LogFilter{}.query().Encode(), "sort=time")
+	})
+	t.Run("Filter fields are translated to query parameters", func(t *testing.T) {
+		query := LogFilter{
+			Start:              time.Unix(100, 0),
+			End:                time.Unix(200, 0),
+			HostName:           "localhost",
+			ServiceDescription: "Ping",
+			Kinds:              []LogKind{LogAlert, LogExternalCommand},
+		}.query()
+
+		assert.Equal(t, query.Get("time[gte]"), "100")
+		assert.Equal(t, query.Get("time[lt]"), "200")
+		assert.Equal(t, query.Get("host_name"), "localhost")
+		assert.Equal(t, query.Get("service_description"), "Ping")
+		assert.Equal(t, query.Get("type[regex]"), "^(HOST ALERT|SERVICE ALERT|EXTERNAL COMMAND)$")
+	})
+}
+
+func Test_thruk_client_Logs(t *testing.T) {
+	t.Run("List logs of the last hour", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.ListLogs(LogFilter{Start: time.Now().Add(-time.Hour)})
+		assert.NilError(t, err)
+	})
+	t.Run("Iterate alerts returns only alerts", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		it := thruk.IterateAlerts(context.Background(), LogFilter{Start: time.Now().Add(-time.Hour)})
+		for it.Next() {
+			assert.Equal(t, it.Entry().Kind(), LogAlert)
+		}
+		assert.NilError(t, it.Err())
+	})
+}
@@ -0,0 +1,107 @@
+package thruk
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Notification is a host or service notification sent to a contact.
+type Notification struct {
+	Time               time.Time
+	ContactName        string
+	CommandName        string
+	HostName           string
+	ServiceDescription string
+	State              int
+	// Reason is the state field of the log line, e.g. "CRITICAL",
+	// "RECOVERY" or "ACKNOWLEDGEMENT (CRITICAL)".
+	Reason string
+	Output string
+}
+
+func (n Notification) IsHostNotification() bool {
+	return n.ServiceDescription == ""
+}
+
+// notificationFromLog fills the notification from the log entry columns and
+// falls back to the message for anything the backend left empty. Messages
+// look like
+//
+//	SERVICE NOTIFICATION: contact;host;service;state;command;output
+//	HOST NOTIFICATION: contact;host;state;command;output
+func notificationFromLog(e LogEntry) Notification {
+	n := Notification{
+		Time:               e.Timestamp(),
+		ContactName:        e.ContactName,
+		CommandName:        e.CommandName,
+		HostName:           e.HostName,
+		ServiceDescription: e.ServiceDescription,
+		State:              e.State,
+		Output:             e.PluginOutput,
+	}
+
+	message := e.Message
+	if i := strings.Index(message, "NOTIFICATION: "); i >= 0 {
+		message = message[i+len("NOTIFICATION: "):]
+	}
+	var fields []string
+	if e.Type == "HOST NOTIFICATION" {
+		fields = strings.SplitN(message, ";", 5)
+		if len(fields) >= 2 {
+			fields = append(fields[:2], append([]string{""}, fields[2:]...)...)
+		}
+	} else {
+		fields = strings.SplitN(message, ";", 6)
+	}
+	if len(fields) < 5 {
+		return n
+	}
+	set := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	set(&n.ContactName, fields[0])
+	set(&n.HostName, fields[1])
+	set(&n.ServiceDescription, fields[2])
+	n.Reason = fields[3]
+	set(&n.CommandName, fields[4])
+	if len(fields) == 6 {
+		set(&n.Output, fields[5])
+	}
+	return n
+}
+
+// NotificationIterator pages through notifications like LogIterator.
+type NotificationIterator struct {
+	logs *LogIterator
+}
+
+func (it *NotificationIterator) Next() bool {
+	return it.logs.Next()
+}
+
+func (it *NotificationIterator) Notification() Notification {
+	return notificationFromLog(it.logs.Entry())
+}
+
+func (it *NotificationIterator) Err() error {
+	return it.logs.Err()
+}
+
+// IterateNotifications streams the notifications from /notifications
+// matching filter. Kinds is ignored.
+func (t Thruk) IterateNotifications(ctx context.Context, filter LogFilter) *NotificationIterator {
+	filter.Kinds = nil
+	return &NotificationIterator{logs: &LogIterator{objects: t.Iterate(ctx, "notifications", filter.query())}}
+}
+
+func (t Thruk) ListNotifications(filter LogFilter) ([]Notification, error) {
+	var notifications []Notification
+	it := t.IterateNotifications(context.Background(), filter)
+	for it.Next() {
+		notifications = append(notifications, it.Notification())
+	}
+	return notifications, it.Err()
+}
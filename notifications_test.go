@@ -0,0 +1,62 @@
+package thruk
+
+import (
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+func Test_notificationFromLog(t *testing.T) {
+	t.Run("Service notification fields are read from the message", func(t *testing.T) {
+		n := notificationFromLog(LogEntry{
+			Time:    100,
+			Type:    "SERVICE NOTIFICATION",
+			State:   2,
+			Message: "[100] SERVICE NOTIFICATION: oncall;web01;HTTP;CRITICAL;notify-service-by-email;connect failed; retrying",
+		})
+
+		assert.DeepEqual(t, n, Notification{
+			Time:               time.Unix(100, 0),
+			ContactName:        "oncall",
+			CommandName:        "notify-service-by-email",
+			HostName:           "web01",
+			ServiceDescription: "HTTP",
+			State:              2,
+			Reason:             "CRITICAL",
+			Output:             "connect failed; retrying",
+		})
+		assert.Assert(t, !n.IsHostNotification())
+	})
+	t.Run("Host notification has no service", func(t *testing.T) {
+		n := notificationFromLog(LogEntry{
+			Type:     "HOST NOTIFICATION",
+			HostName: "web01",
+			Message:  "HOST NOTIFICATION: oncall;web01;ACKNOWLEDGEMENT (DOWN);notify-host-by-email;PING CRITICAL",
+		})
+
+		assert.Equal(t, n.ContactName, "oncall")
+		assert.Equal(t, n.Reason, "ACKNOWLEDGEMENT (DOWN)")
+		assert.Equal(t, n.CommandName, "notify-host-by-email")
+		assert.Equal(t, n.Output, "PING CRITICAL")
+		assert.Assert(t, n.IsHostNotification())
+	})
+	t.Run("Columns from the backend take precedence over the message", func(t *testing.T) {
+		n := notificationFromLog(LogEntry{
+			Type:        "HOST NOTIFICATION",
+			ContactName: "admin",
+			Message:     "HOST NOTIFICATION: oncall;web01;DOWN;notify-host-by-email;PING CRITICAL",
+		})
+
+		assert.Equal(t, n.ContactName, "admin")
+		assert.Equal(t, n.HostName, "web01")
+	})
+}
+
+func Test_thruk_client_Notifications(t *testing.T) {
+	t.Run("List notifications of the last day", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.ListNotifications(LogFilter{Start: time.Now().Add(-24 * time.Hour), ContactName: "thrukadmin"})
+		assert.NilError(t, err)
+	})
+}
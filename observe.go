@@ -0,0 +1,133 @@
+package thruk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestInfo describes a request to Thruk for an Observer.
+type RequestInfo struct {
+	Method string
+	// Endpoint is the path below the site with IDs replaced by
+	// placeholders, e.g. "/thruk/r/config/objects/{id}", so requests can be
+	// grouped by it.
+	Endpoint string
+	// ObjectType is the type of the config objects requested, when known
+	// from a :TYPE filter or the request body.
+	ObjectType string
+	// Header are the headers of the request. Observers may add to them,
+	// e.g. to propagate a trace context.
+	Header http.Header
+}
+
+type RequestResult struct {
+	// StatusCode is 0 when no response was received.
+	StatusCode int
+	// Err is the error of a failed request or of an error status.
+	Err      error
+	Duration time.Duration
+}
+
+// Observer is notified about every request of a client, e.g. to trace
+// requests or to collect metrics.
+type Observer interface {
+	// StartRequest is called before a request is sent, including the time
+	// spent waiting for the limits of the client. The request continues
+	// with the returned context and the returned func is called with the
+	// outcome.
+	StartRequest(ctx context.Context, info RequestInfo) (context.Context, func(RequestResult))
+}
+
+func WithObserver(observer Observer) Option {
+	return func(t *Thruk) {
+		t.observer = observer
+	}
+}
+
+// observe wraps a request in the observer of the client.
+func (t Thruk) observe(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if t.observer == nil {
+		return send(req)
+	}
+	info := RequestInfo{
+		Method:     req.Method,
+		Endpoint:   endpointTemplate(strings.TrimPrefix(req.URL.Path, "/"+t.SiteName)),
+		ObjectType: requestObjectType(req),
+		Header:     req.Header,
+	}
+	ctx, done := t.observer.StartRequest(req.Context(), info)
+	start := time.Now()
+	resp, err := send(req.WithContext(ctx))
+	result := RequestResult{Err: err, Duration: time.Since(start)}
+	if err == nil {
+		result.StatusCode = resp.StatusCode
+		if resp.StatusCode >= 400 {
+			result.Err = errors.New(resp.Status)
+		}
+	}
+	done(result)
+	return resp, err
+}
+
+// endpointTemplate replaces the IDs of config objects and the numbers of
+// reports, business processes and dashboards in path by placeholders.
+func endpointTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments); i++ {
+		switch {
+		case segments[i] == "":
+		case segments[i-1] == "objects" && i >= 2 && segments[i-2] == "config":
+			segments[i] = "{id}"
+		case i >= 2 && segments[i-2] == "thruk" && isNumber(segments[i]):
+			segments[i] = "{nr}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isNumber(text string) bool {
+	if text == "" {
+		return false
+	}
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestObjectType finds the object type in the :TYPE filter or in the
+// JSON body of the request, which may hold an object or a list of them.
+func requestObjectType(req *http.Request) string {
+	if objectType := req.URL.Query().Get(":TYPE"); objectType != "" {
+		return objectType
+	}
+	if !strings.Contains(req.URL.Path, "/config/objects") || req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return ""
+	}
+	type header struct {
+		TYPE string `json:":TYPE"`
+	}
+	var objects []header
+	if json.Unmarshal(data, &objects) == nil && len(objects) > 0 {
+		return objects[0].TYPE
+	}
+	var object header
+	json.Unmarshal(data, &object)
+	return object.TYPE
+}
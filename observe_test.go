@@ -0,0 +1,78 @@
+package thruk
+
+import (
+	"context"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type recordedRequest struct {
+	info   RequestInfo
+	result RequestResult
+}
+
+type recordingObserver struct {
+	requests []recordedRequest
+}
+
+func (o *recordingObserver) StartRequest(ctx context.Context, info RequestInfo) (context.Context, func(RequestResult)) {
+	info.Header.Set("X-Observed", "yes")
+	return ctx, func(result RequestResult) {
+		o.requests = append(o.requests, recordedRequest{info, result})
+	}
+}
+
+func Test_Observer(t *testing.T) {
+	t.Run("Requests are reported with endpoint, object type and status", func(t *testing.T) {
+		var observedHeader string
+		config := &fakeConfig{objects: []map[string]interface{}{{":ID": "h1", ":TYPE": "host", "host_name": "web01"}}}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			observedHeader = r.Header.Get("X-Observed")
+			if r.Method == "DELETE" {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			config.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+		observer := &recordingObserver{}
+		thruk := NewThruk(server.URL, "site", "user", "pass", false, WithObserver(observer))
+
+		_, err := thruk.GetHost("h1")
+		assert.NilError(t, err)
+		thruk.CreateHost(Host{FILE: "hosts.cfg", TYPE: "host", HostName: "web02"})
+		assert.ErrorContains(t, thruk.DeleteConfigObject("h1"), "404")
+
+		assert.Equal(t, observedHeader, "yes")
+		assert.Equal(t, len(observer.requests), 3)
+		get, create, del := observer.requests[0], observer.requests[1], observer.requests[2]
+		assert.Equal(t, get.info.Method, "GET")
+		assert.Equal(t, get.info.Endpoint, "/thruk/r/config/objects")
+		assert.Equal(t, get.info.ObjectType, "host")
+		assert.Equal(t, get.result.StatusCode, 200)
+		assert.NilError(t, get.result.Err)
+		assert.Equal(t, create.info.Method, "POST")
+		assert.Equal(t, create.info.ObjectType, "host")
+		assert.Equal(t, del.info.Endpoint, "/thruk/r/config/objects/{id}")
+		assert.Equal(t, del.result.StatusCode, 404)
+		assert.ErrorContains(t, del.result.Err, "404")
+	})
+}
+
+func Test_endpointTemplate(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/thruk/r/hosts":                      "/thruk/r/hosts",
+		"/thruk/r/config/objects/abc123":      "/thruk/r/config/objects/{id}",
+		"/thruk/r/config/discard":             "/thruk/r/config/discard",
+		"/thruk/r/thruk/reports/3/generate":   "/thruk/r/thruk/reports/{nr}/generate",
+		"/thruk/r/thruk/bp/12":                "/thruk/r/thruk/bp/{nr}",
+		"/thruk/r/config/objects/":            "/thruk/r/config/objects/",
+		"/thruk/r/thruk/panorama/7/something": "/thruk/r/thruk/panorama/{nr}/something",
+	} {
+		t.Run(path, func(t *testing.T) {
+			assert.Equal(t, endpointTemplate(path), expected)
+		})
+	}
+}
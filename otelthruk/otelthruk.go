@@ -0,0 +1,140 @@
+// Package otelthruk instruments Thruk clients with OpenTelemetry. Every
+// request becomes a client span and is recorded in the request duration
+// and error metrics:
+//
+//	observer, err := otelthruk.NewObserver()
+//	if err != nil {
+//		...
+//	}
+//	client := thruk.NewThruk(URL, site, user, password, false, thruk.WithObserver(observer))
+//
+// The package is a module of its own, so the client does not depend on
+// OpenTelemetry.
+package otelthruk
+
+import (
+	"context"
+	"strconv"
+
+	thruk "gitlab.com/roviluca/thruk-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "gitlab.com/roviluca/thruk-go/otelthruk"
+
+const (
+	MethodKey     = attribute.Key("http.request.method")
+	EndpointKey   = attribute.Key("url.template")
+	StatusCodeKey = attribute.Key("http.response.status_code")
+	ErrorTypeKey  = attribute.Key("error.type")
+	ObjectTypeKey = attribute.Key("thruk.object_type")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+}
+
+type Option func(*config)
+
+// WithTracerProvider sets the provider of the tracer, the global one when
+// not set.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the provider of the meter, the global one when
+// not set.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// WithPropagators sets the propagators injecting the trace context into
+// the request headers, the global ones when not set.
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = propagators
+	}
+}
+
+// Observer implements thruk.Observer.
+type Observer struct {
+	tracer      trace.Tracer
+	propagators propagation.TextMapPropagator
+	duration    metric.Float64Histogram
+	errors      metric.Int64Counter
+}
+
+var _ thruk.Observer = (*Observer)(nil)
+
+func NewObserver(options ...Option) (*Observer, error) {
+	c := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagators:    otel.GetTextMapPropagator(),
+	}
+	for _, option := range options {
+		option(&c)
+	}
+	meter := c.meterProvider.Meter(instrumentationName)
+	duration, err := meter.Float64Histogram("thruk.client.request.duration",
+		metric.WithDescription("Duration of requests to Thruk"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	errors, err := meter.Int64Counter("thruk.client.request.errors",
+		metric.WithDescription("Number of failed requests to Thruk, including error statuses"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return nil, err
+	}
+	return &Observer{
+		tracer:      c.tracerProvider.Tracer(instrumentationName),
+		propagators: c.propagators,
+		duration:    duration,
+		errors:      errors,
+	}, nil
+}
+
+// StartRequest starts a span named after the method and endpoint, e.g.
+// "DELETE /thruk/r/config/objects/{id}".
+func (o *Observer) StartRequest(ctx context.Context, info thruk.RequestInfo) (context.Context, func(thruk.RequestResult)) {
+	attributes := []attribute.KeyValue{MethodKey.String(info.Method), EndpointKey.String(info.Endpoint)}
+	if info.ObjectType != "" {
+		attributes = append(attributes, ObjectTypeKey.String(info.ObjectType))
+	}
+	ctx, span := o.tracer.Start(ctx, info.Method+" "+info.Endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...))
+	o.propagators.Inject(ctx, propagation.HeaderCarrier(info.Header))
+
+	return ctx, func(result thruk.RequestResult) {
+		defer span.End()
+		if result.StatusCode != 0 {
+			attributes = append(attributes, StatusCodeKey.Int(result.StatusCode))
+		}
+		if result.Err != nil {
+			errorType := "error"
+			if result.StatusCode != 0 {
+				errorType = strconv.Itoa(result.StatusCode)
+			}
+			attributes = append(attributes, ErrorTypeKey.String(errorType))
+			span.RecordError(result.Err)
+			span.SetStatus(codes.Error, result.Err.Error())
+			o.errors.Add(ctx, 1, metric.WithAttributes(attributes...))
+		}
+		span.SetAttributes(attributes...)
+		o.duration.Record(ctx, result.Duration.Seconds(), metric.WithAttributes(attributes...))
+	}
+}
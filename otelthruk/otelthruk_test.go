@@ -0,0 +1,109 @@
+package otelthruk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	thruk "gitlab.com/roviluca/thruk-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gotest.tools/assert"
+)
+
+func startInstrumentedClient(t *testing.T, handler http.HandlerFunc) (*thruk.Thruk, *tracetest.SpanRecorder, *sdkmetric.ManualReader, func()) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	observer, err := NewObserver(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithPropagators(propagation.TraceContext{}))
+	assert.NilError(t, err)
+	server := httptest.NewServer(handler)
+	client := thruk.NewThruk(server.URL, "site", "user", "pass", false, thruk.WithObserver(observer))
+	return client, spans, reader, server.Close
+}
+
+func attributeValue(attributes []attribute.KeyValue, key attribute.Key) attribute.Value {
+	for _, kv := range attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func Test_Observer(t *testing.T) {
+	t.Run("Requests become client spans", func(t *testing.T) {
+		var traceparent string
+		client, spans, _, closeServer := startInstrumentedClient(t, func(w http.ResponseWriter, r *http.Request) {
+			traceparent = r.Header.Get("traceparent")
+			w.Write([]byte(`[{":ID":"h1",":TYPE":"host","host_name":"web01"}]`))
+		})
+		defer closeServer()
+
+		_, err := client.GetConfigObject("h1")
+		assert.NilError(t, err)
+
+		ended := spans.Ended()
+		assert.Equal(t, len(ended), 1)
+		span := ended[0]
+		assert.Equal(t, span.Name(), "GET /thruk/r/config/objects")
+		assert.Equal(t, attributeValue(span.Attributes(), MethodKey).AsString(), "GET")
+		assert.Equal(t, attributeValue(span.Attributes(), EndpointKey).AsString(), "/thruk/r/config/objects")
+		assert.Equal(t, attributeValue(span.Attributes(), StatusCodeKey).AsInt64(), int64(200))
+		assert.Equal(t, span.Status().Code, codes.Unset)
+		assert.Assert(t, traceparent != "")
+		assert.Equal(t, traceparent[3:35], span.SpanContext().TraceID().String())
+	})
+
+	t.Run("Error statuses are recorded on spans and counted", func(t *testing.T) {
+		client, spans, reader, closeServer := startInstrumentedClient(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "no such object", http.StatusNotFound)
+		})
+		defer closeServer()
+
+		assert.Assert(t, client.DeleteConfigObject("h1") != nil)
+
+		span := spans.Ended()[0]
+		assert.Equal(t, span.Name(), "DELETE /thruk/r/config/objects/{id}")
+		assert.Equal(t, span.Status().Code, codes.Error)
+		assert.Equal(t, attributeValue(span.Attributes(), ErrorTypeKey).AsString(), "404")
+		assert.Equal(t, len(span.Events()), 1)
+
+		var data metricdata.ResourceMetrics
+		assert.NilError(t, reader.Collect(context.Background(), &data))
+		metrics := map[string]metricdata.Metrics{}
+		for _, scope := range data.ScopeMetrics {
+			for _, m := range scope.Metrics {
+				metrics[m.Name] = m
+			}
+		}
+		errors := metrics["thruk.client.request.errors"].Data.(metricdata.Sum[int64])
+		assert.Equal(t, len(errors.DataPoints), 1)
+		assert.Equal(t, errors.DataPoints[0].Value, int64(1))
+		status, _ := errors.DataPoints[0].Attributes.Value(StatusCodeKey)
+		assert.Equal(t, status.AsInt64(), int64(404))
+		duration := metrics["thruk.client.request.duration"].Data.(metricdata.Histogram[float64])
+		assert.Equal(t, duration.DataPoints[0].Count, uint64(1))
+	})
+
+	t.Run("Created objects carry their type", func(t *testing.T) {
+		client, spans, _, closeServer := startInstrumentedClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"objects":[{":ID":"h2"}]}`))
+		})
+		defer closeServer()
+
+		client.CreateHost(thruk.Host{FILE: "hosts.cfg", TYPE: "host", HostName: "web02"})
+
+		span := spans.Ended()[0]
+		assert.Equal(t, span.Name(), "POST /thruk/r/config/objects/")
+		assert.Equal(t, attributeValue(span.Attributes(), ObjectTypeKey).AsString(), "host")
+	})
+}
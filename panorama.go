@@ -0,0 +1,274 @@
+package thruk
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Panlet is a single widget of a Panorama dashboard. XType is the name of
+// the panlet class in Thruk, XData its settings.
+type Panlet struct {
+	XType  string
+	Title  string
+	X      int
+	Y      int
+	Width  int
+	Height int
+	XData  map[string]interface{}
+}
+
+const (
+	PanletHostStatus  = "TP.HostStatusIcon"
+	PanletServiceGrid = "TP.PanletGridServices"
+	PanletBPStatus    = "TP.BPStatusIcon"
+)
+
+// HostStatusPanlet is an icon showing the state of a host.
+func HostStatusPanlet(host string) Panlet {
+	return Panlet{
+		XType:  PanletHostStatus,
+		Title:  host,
+		Width:  22,
+		Height: 22,
+		XData:  map[string]interface{}{"general": map[string]interface{}{"host": host}},
+	}
+}
+
+// ServiceGridPanlet lists the services matching a Thruk status filter, e.g.
+// "host_name = web01".
+func ServiceGridPanlet(title, filter string) Panlet {
+	return Panlet{
+		XType:  PanletServiceGrid,
+		Title:  title,
+		Width:  640,
+		Height: 240,
+		XData:  map[string]interface{}{"term": filter},
+	}
+}
+
+// BPStatusPanlet is an icon showing the state of a business process.
+func BPStatusPanlet(nr int) Panlet {
+	return Panlet{
+		XType:  PanletBPStatus,
+		Title:  "bp " + strconv.Itoa(nr),
+		Width:  22,
+		Height: 22,
+		XData:  map[string]interface{}{"general": map[string]interface{}{"bp": strconv.Itoa(nr)}},
+	}
+}
+
+// At returns a copy of the panlet placed at x, y.
+func (p Panlet) At(x, y int) Panlet {
+	p.X, p.Y = x, y
+	return p
+}
+
+// Sized returns a copy of the panlet with the given size.
+func (p Panlet) Sized(width, height int) Panlet {
+	p.Width, p.Height = width, height
+	return p
+}
+
+type Dashboard struct {
+	NR      int
+	Title   string
+	Owner   string
+	Refresh int
+	Panlets []Panlet
+}
+
+func NewDashboard(title string) *Dashboard {
+	return &Dashboard{Title: title}
+}
+
+// Add appends panlets to the dashboard and returns it for chaining.
+func (d *Dashboard) Add(panlets ...Panlet) *Dashboard {
+	d.Panlets = append(d.Panlets, panlets...)
+	return d
+}
+
+type panoramaEntry struct {
+	XType string                 `json:"xtype,omitempty"`
+	XData map[string]interface{} `json:"xdata"`
+}
+
+// MarshalJSON writes the dashboard in the format of Thruk's dashboard
+// files: a "tab" entry for the dashboard itself and one "panlet_<n>" entry
+// per panlet.
+func (d Dashboard) MarshalJSON() ([]byte, error) {
+	tab := map[string]interface{}{"title": d.Title}
+	if d.Owner != "" {
+		tab["owner"] = d.Owner
+	}
+	if d.Refresh > 0 {
+		tab["refresh"] = d.Refresh
+	}
+	file := map[string]interface{}{"tab": panoramaEntry{XData: tab}}
+	if d.NR > 0 {
+		file["nr"] = d.NR
+	}
+	for i, p := range d.Panlets {
+		xdata := map[string]interface{}{}
+		for key, value := range p.XData {
+			xdata[key] = value
+		}
+		xdata["title"] = p.Title
+		xdata["pos"] = []int{p.X, p.Y}
+		xdata["size"] = []int{p.Width, p.Height}
+		file["panlet_"+strconv.Itoa(i+1)] = panoramaEntry{XType: p.XType, XData: xdata}
+	}
+	return json.Marshal(file)
+}
+
+func (d *Dashboard) UnmarshalJSON(data []byte) error {
+	var file map[string]json.RawMessage
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	*d = Dashboard{}
+	if raw, ok := file["nr"]; ok {
+		var nr interface{}
+		if err := json.Unmarshal(raw, &nr); err != nil {
+			return err
+		}
+		switch value := nr.(type) {
+		case float64:
+			d.NR = int(value)
+		case string:
+			d.NR, _ = strconv.Atoi(value)
+		}
+	}
+	if raw, ok := file["tab"]; ok {
+		var tab panoramaEntry
+		if err := json.Unmarshal(raw, &tab); err != nil {
+			return err
+		}
+		d.Title, _ = tab.XData["title"].(string)
+		d.Owner, _ = tab.XData["owner"].(string)
+		if refresh, ok := tab.XData["refresh"].(float64); ok {
+			d.Refresh = int(refresh)
+		}
+	}
+
+	var keys []string
+	for key := range file {
+		if strings.Contains(key, "panlet_") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return panletNumber(keys[i]) < panletNumber(keys[j]) })
+	for _, key := range keys {
+		var entry panoramaEntry
+		if err := json.Unmarshal(file[key], &entry); err != nil {
+			return err
+		}
+		p := Panlet{XType: entry.XType, XData: entry.XData}
+		p.Title, _ = entry.XData["title"].(string)
+		p.X, p.Y = pair(entry.XData["pos"])
+		p.Width, p.Height = pair(entry.XData["size"])
+		delete(p.XData, "title")
+		delete(p.XData, "pos")
+		delete(p.XData, "size")
+		d.Panlets = append(d.Panlets, p)
+	}
+	return nil
+}
+
+func panletNumber(key string) int {
+	n, _ := strconv.Atoi(key[strings.LastIndex(key, "_")+1:])
+	return n
+}
+
+func pair(value interface{}) (int, int) {
+	values, _ := value.([]interface{})
+	if len(values) != 2 {
+		return 0, 0
+	}
+	first, _ := values[0].(float64)
+	second, _ := values[1].(float64)
+	return int(first), int(second)
+}
+
+func (t Thruk) panoramaURL(nr int) string {
+	URL := "/" + t.SiteName + "/thruk/r/thruk/panorama"
+	if nr > 0 {
+		URL += "/" + strconv.Itoa(nr)
+	}
+	return URL
+}
+
+func (t Thruk) ListDashboards() ([]Dashboard, error) {
+	var dashboards []Dashboard
+	err := t.getJSON(t.panoramaURL(0), &dashboards)
+	return dashboards, err
+}
+
+// ExportDashboard returns a dashboard unchanged as Thruk stores it, including
+// settings Dashboard does not know about.
+func (t Thruk) ExportDashboard(nr int) (json.RawMessage, error) {
+	if nr <= 0 {
+		return nil, ErrorNeedID
+	}
+	var dashboards []json.RawMessage
+	if err := t.getJSON(t.panoramaURL(nr), &dashboards); err != nil {
+		return nil, err
+	}
+	if len(dashboards) == 0 {
+		return nil, ErrorObjectNotFound
+	}
+	return dashboards[0], nil
+}
+
+// ImportDashboard creates a dashboard from an export and returns its number.
+// The number stored in the export is ignored.
+func (t Thruk) ImportDashboard(data json.RawMessage) (int, error) {
+	var file map[string]json.RawMessage
+	if err := json.Unmarshal(data, &file); err != nil {
+		return 0, err
+	}
+	if _, ok := file["tab"]; !ok {
+		return 0, ErrorInvalidInput
+	}
+	delete(file, "nr")
+	delete(file, "id")
+	resp, err := t.sendJSON("POST", t.panoramaURL(0), file)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	created := struct {
+		NR int `json:"nr"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return 0, err
+	}
+	if created.NR == 0 {
+		return 0, errors.New("dashboard not created")
+	}
+	return created.NR, nil
+}
+
+// CreateDashboard creates a dashboard, e.g. one built with NewDashboard,
+// and returns its number.
+func (t Thruk) CreateDashboard(d Dashboard) (int, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return 0, err
+	}
+	return t.ImportDashboard(data)
+}
+
+func (t Thruk) DeleteDashboard(nr int) error {
+	if nr <= 0 {
+		return ErrorNeedID
+	}
+	resp, err := t.sendJSON("DELETE", t.panoramaURL(nr), nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
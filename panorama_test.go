@@ -0,0 +1,74 @@
+package thruk
+
+import (
+	"encoding/json"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func Test_Dashboard_json(t *testing.T) {
+	t.Run("Built dashboards round trip through the Thruk format", func(t *testing.T) {
+		d := NewDashboard("NOC").Add(
+			HostStatusPanlet("web01").At(10, 20),
+			ServiceGridPanlet("web services", "host_name = web01").At(0, 50).Sized(800, 300),
+			BPStatusPanlet(3).At(40, 20),
+		)
+
+		encoded, err := json.Marshal(d)
+		assert.NilError(t, err)
+		var file map[string]map[string]interface{}
+		assert.NilError(t, json.Unmarshal(encoded, &file))
+		assert.Equal(t, file["tab"]["xdata"].(map[string]interface{})["title"], "NOC")
+		assert.Equal(t, file["panlet_1"]["xtype"], PanletHostStatus)
+
+		var decoded Dashboard
+		assert.NilError(t, json.Unmarshal(encoded, &decoded))
+		assert.Equal(t, decoded.Title, "NOC")
+		assert.Equal(t, len(decoded.Panlets), 3)
+		assert.Equal(t, decoded.Panlets[1].XType, PanletServiceGrid)
+		assert.Equal(t, decoded.Panlets[1].Title, "web services")
+		assert.Equal(t, decoded.Panlets[1].Width, 800)
+		assert.Equal(t, decoded.Panlets[1].Y, 50)
+		assert.Equal(t, decoded.Panlets[1].XData["term"], "host_name = web01")
+		assert.Equal(t, decoded.Panlets[2].X, 40)
+	})
+	t.Run("Panlets are ordered by number and string numbers are read", func(t *testing.T) {
+		var d Dashboard
+		err := json.Unmarshal([]byte(`{"nr":"7","tab":{"xdata":{"title":"t","owner":"admin"}},"panlet_10":{"xtype":"b","xdata":{}},"panlet_2":{"xtype":"a","xdata":{}}}`), &d)
+		assert.NilError(t, err)
+		assert.Equal(t, d.NR, 7)
+		assert.Equal(t, d.Owner, "admin")
+		assert.Equal(t, d.Panlets[0].XType, "a")
+		assert.Equal(t, d.Panlets[1].XType, "b")
+	})
+}
+
+func Test_thruk_client_Panorama(t *testing.T) {
+	t.Run("Create, export, import and delete a dashboard", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		nr, err := thruk.CreateDashboard(*NewDashboard("test").Add(HostStatusPanlet("localhost")))
+		assert.NilError(t, err)
+
+		exported, err := thruk.ExportDashboard(nr)
+		assert.NilError(t, err)
+		imported, err := thruk.ImportDashboard(exported)
+		assert.NilError(t, err)
+		assert.Assert(t, imported != nr)
+
+		dashboards, err := thruk.ListDashboards()
+		assert.NilError(t, err)
+		assert.Assert(t, len(dashboards) >= 2)
+
+		assert.NilError(t, thruk.DeleteDashboard(nr))
+		assert.NilError(t, thruk.DeleteDashboard(imported))
+		_, err = thruk.ExportDashboard(nr)
+		assert.Assert(t, err != nil)
+	})
+	t.Run("Import without tab is rejected", func(t *testing.T) {
+		thruk := NewThruk("http://localhost", "site", "user", "pass", false)
+
+		_, err := thruk.ImportDashboard(json.RawMessage(`{"panlet_1":{}}`))
+		assert.Equal(t, err, ErrorInvalidInput)
+	})
+}
@@ -0,0 +1,257 @@
+package thruk
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PerfDatum is a single item of Nagios performance data:
+//
+//	'label'=value[UOM];[warn];[crit];[min];[max]
+//
+// Warn and Crit are kept as the raw threshold ranges. Value is NaN when
+// the plugin reported "U" for an unknown value, Min and Max are nil when
+// not given.
+type PerfDatum struct {
+	Label string
+	Value float64
+	UOM   string
+	Warn  string
+	Crit  string
+	Min   *float64
+	Max   *float64
+}
+
+// unitScales converts units to their base unit: seconds for times and
+// bytes for sizes, which use multiples of 1024 like the monitoring plugins.
+var unitScales = map[string]struct {
+	base  string
+	scale float64
+}{
+	"s":  {"s", 1},
+	"ms": {"s", 1e-3},
+	"us": {"s", 1e-6},
+	"µs": {"s", 1e-6},
+	"ns": {"s", 1e-9},
+	"B":  {"B", 1},
+	"KB": {"B", 1 << 10},
+	"kB": {"B", 1 << 10},
+	"MB": {"B", 1 << 20},
+	"GB": {"B", 1 << 30},
+	"TB": {"B", 1 << 40},
+}
+
+// ParsePerfData parses a performance data string. Items that cannot be
+// parsed are skipped; the error describes the first of them.
+func ParsePerfData(perfData string) ([]PerfDatum, error) {
+	var data []PerfDatum
+	var firstErr error
+	rest := strings.Trim(perfData, " \t\r\n")
+	for rest != "" {
+		var item string
+		var err error
+		item, rest, err = nextPerfItem(rest)
+		if err == nil {
+			var datum PerfDatum
+			datum, err = parsePerfItem(item)
+			if err == nil {
+				data = append(data, datum)
+			}
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		rest = strings.TrimLeft(rest, " \t\r\n")
+	}
+	return data, firstErr
+}
+
+// quotedLength returns the length of the quoted label text starts with, in
+// which a quote is written as two quotes, or -1 if it is not terminated.
+func quotedLength(text string) int {
+	i := 1
+	for {
+		end := strings.IndexByte(text[i:], '\'')
+		if end < 0 {
+			return -1
+		}
+		i += end + 1
+		if i < len(text) && text[i] == '\'' {
+			i++
+			continue
+		}
+		return i
+	}
+}
+
+// nextPerfItem splits off the first item. Quoted labels may contain spaces.
+func nextPerfItem(text string) (item, rest string, err error) {
+	i := 0
+	if text[0] == '\'' {
+		if i = quotedLength(text); i < 0 {
+			return text, "", fmt.Errorf("unterminated label in perfdata %q", text)
+		}
+	}
+	if end := strings.IndexAny(text[i:], " \t\r\n"); end >= 0 {
+		return text[:i+end], text[i+end:], nil
+	}
+	return text, "", nil
+}
+
+func parsePerfItem(item string) (PerfDatum, error) {
+	var datum PerfDatum
+	var rest string
+	if item[0] == '\'' {
+		end := quotedLength(item)
+		if end < 0 || end >= len(item) || item[end] != '=' {
+			return datum, fmt.Errorf("invalid perfdata %q", item)
+		}
+		datum.Label = strings.Replace(item[1:end-1], "''", "'", -1)
+		rest = item[end+1:]
+	} else {
+		eq := strings.IndexByte(item, '=')
+		if eq <= 0 {
+			return datum, fmt.Errorf("invalid perfdata %q", item)
+		}
+		datum.Label = item[:eq]
+		rest = item[eq+1:]
+	}
+
+	if datum.Label == "" {
+		return datum, fmt.Errorf("invalid perfdata %q", item)
+	}
+
+	fields := strings.Split(rest, ";")
+	value := fields[0]
+	end := 0
+	if strings.HasPrefix(value, "U") {
+		datum.Value = math.NaN()
+		end = 1
+	} else {
+		// the value is the longest number at the start, the unit follows
+		end = strings.IndexFunc(value, func(r rune) bool {
+			return !strings.ContainsRune("0123456789.,-+eE", r)
+		})
+		if end < 0 {
+			end = len(value)
+		}
+		for ; end > 0; end-- {
+			if parsed, err := parsePerfNumber(value[:end]); err == nil {
+				datum.Value = parsed
+				break
+			}
+		}
+		if end == 0 || end < len(value) && strings.ContainsRune("0123456789.,", rune(value[end])) {
+			return datum, fmt.Errorf("invalid value in perfdata %q", item)
+		}
+	}
+	datum.UOM = value[end:]
+
+	if len(fields) > 1 {
+		datum.Warn = fields[1]
+	}
+	if len(fields) > 2 {
+		datum.Crit = fields[2]
+	}
+	for i, bound := range []**float64{&datum.Min, &datum.Max} {
+		if len(fields) <= 3+i || fields[3+i] == "" {
+			continue
+		}
+		// some plugins repeat the unit on min and max
+		number := strings.TrimSuffix(fields[3+i], datum.UOM)
+		parsed, err := parsePerfNumber(number)
+		if err != nil {
+			return datum, fmt.Errorf("invalid min or max in perfdata %q", item)
+		}
+		*bound = &parsed
+	}
+	return datum, nil
+}
+
+// parsePerfNumber parses a number, accepting a decimal comma as written by
+// plugins running in some locales.
+func parsePerfNumber(text string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.Replace(text, ",", ".", 1), 64)
+	if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("invalid number %q", text)
+	}
+	return value, nil
+}
+
+// Normalized returns the datum converted to its base unit, seconds for
+// times and bytes for sizes, including thresholds and bounds. Other units
+// like %, c or unknown ones are returned unchanged.
+func (d PerfDatum) Normalized() PerfDatum {
+	unit, ok := unitScales[d.UOM]
+	if !ok || unit.scale == 1 && unit.base == d.UOM {
+		return d
+	}
+	d.UOM = unit.base
+	d.Value *= unit.scale
+	d.Warn = scaleRange(d.Warn, unit.scale)
+	d.Crit = scaleRange(d.Crit, unit.scale)
+	for _, bound := range []**float64{&d.Min, &d.Max} {
+		if *bound != nil {
+			scaled := **bound * unit.scale
+			*bound = &scaled
+		}
+	}
+	return d
+}
+
+// scaleRange multiplies the numbers of a threshold range like "@10:~" by
+// scale.
+func scaleRange(threshold string, scale float64) string {
+	if threshold == "" {
+		return threshold
+	}
+	prefix := ""
+	if strings.HasPrefix(threshold, "@") {
+		prefix, threshold = "@", threshold[1:]
+	}
+	parts := strings.SplitN(threshold, ":", 2)
+	for i, part := range parts {
+		if value, err := parsePerfNumber(part); err == nil {
+			parts[i] = formatPerfNumber(value * scale)
+		}
+	}
+	return prefix + strings.Join(parts, ":")
+}
+
+func formatPerfNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// String formats the datum as performance data, omitting trailing empty
+// fields.
+func (d PerfDatum) String() string {
+	label := d.Label
+	if strings.ContainsAny(label, " '=\t\r\n") {
+		label = "'" + strings.Replace(label, "'", "''", -1) + "'"
+	}
+	value := "U" + d.UOM
+	if !math.IsNaN(d.Value) {
+		value = formatPerfNumber(d.Value) + d.UOM
+	}
+	fields := []string{value, d.Warn, d.Crit, "", ""}
+	if d.Min != nil {
+		fields[3] = formatPerfNumber(*d.Min)
+	}
+	if d.Max != nil {
+		fields[4] = formatPerfNumber(*d.Max)
+	}
+	for len(fields) > 1 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+	return label + "=" + strings.Join(fields, ";")
+}
+
+func FormatPerfData(data []PerfDatum) string {
+	items := make([]string, len(data))
+	for i, datum := range data {
+		items[i] = datum.String()
+	}
+	return strings.Join(items, " ")
+}
@@ -0,0 +1,45 @@
+//go:build go1.18
+// +build go1.18
+
+package thruk
+
+import (
+	"math"
+	"testing"
+)
+
+func samePerfDatum(a, b PerfDatum) bool {
+	sameBound := func(x, y *float64) bool {
+		return x == nil && y == nil || x != nil && y != nil && *x == *y
+	}
+	sameValue := a.Value == b.Value || math.IsNaN(a.Value) && math.IsNaN(b.Value)
+	return a.Label == b.Label && sameValue && a.UOM == b.UOM && a.Warn == b.Warn && a.Crit == b.Crit &&
+		sameBound(a.Min, b.Min) && sameBound(a.Max, b.Max)
+}
+
+func FuzzParsePerfData(f *testing.F) {
+	for _, seed := range []string{
+		"'load1'=0.5;5;10;0; time=12ms",
+		"rta=0.028000ms;3000.000000;5000.000000;0.000000 pl=0%;80;100;0",
+		"'C:\\ used space'=26.47Gb;47.18;53.08;0.00;58.98 'C:\\ Used Space %'=45%;80;90;0;100",
+		"/=2643MB;5948;6667;0;7148 /boot=68MB;88;93;0;98",
+		"users=3;10;20;0 procs=172;;;0",
+		"time=0.002139s;;;0.000000 size=1234B;;;0",
+		"'it''s'=U;~:10;@5:7",
+		"load1=0,15;15,000;30,000;0;",
+		"'unterminated=1 x=",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, perfData string) {
+		data, _ := ParsePerfData(perfData)
+		for _, datum := range data {
+			formatted := datum.String()
+			parsed, err := ParsePerfData(formatted)
+			if err != nil || len(parsed) != 1 || !samePerfDatum(parsed[0], datum) {
+				t.Fatalf("%q formatted as %q parses to %v, %v", perfData, formatted, parsed, err)
+			}
+			datum.Normalized()
+		}
+	})
+}
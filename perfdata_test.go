@@ -0,0 +1,71 @@
+package thruk
+
+import (
+	"gotest.tools/assert"
+	"math"
+	"testing"
+)
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func Test_ParsePerfData(t *testing.T) {
+	t.Run("Items with thresholds and bounds", func(t *testing.T) {
+		data, err := ParsePerfData("'load1'=0.5;5;10;0; time=12ms")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, data, []PerfDatum{
+			{Label: "load1", Value: 0.5, Warn: "5", Crit: "10", Min: float(0)},
+			{Label: "time", Value: 12, UOM: "ms"},
+		})
+	})
+	t.Run("Quoted labels may contain spaces, equal signs and quotes", func(t *testing.T) {
+		data, err := ParsePerfData("'/ used'=80%;90;95;0;100 'it''s = x'=1c")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, data, []PerfDatum{
+			{Label: "/ used", Value: 80, UOM: "%", Warn: "90", Crit: "95", Min: float(0), Max: float(100)},
+			{Label: "it's = x", Value: 1, UOM: "c"},
+		})
+	})
+	t.Run("Real world quirks are accepted", func(t *testing.T) {
+		data, err := ParsePerfData("  rta=0,123ms;100.000;500.000;0;  size=2GB;;;0GB;10GB\tpl=U;;;0;100 ")
+		assert.NilError(t, err)
+		assert.Equal(t, len(data), 3)
+		assert.Equal(t, data[0].Value, 0.123)
+		assert.DeepEqual(t, data[1].Max, float(10))
+		assert.Assert(t, math.IsNaN(data[2].Value))
+	})
+	t.Run("Invalid items are skipped and reported", func(t *testing.T) {
+		data, err := ParsePerfData("a=1 b=xyz c=2 'd=3")
+		assert.Error(t, err, `invalid value in perfdata "b=xyz"`)
+		assert.Equal(t, len(data), 2)
+		assert.Equal(t, data[1].Label, "c")
+	})
+}
+
+func Test_PerfDatum_Normalized(t *testing.T) {
+	t.Run("Times are converted to seconds", func(t *testing.T) {
+		d := PerfDatum{Label: "time", Value: 1500, UOM: "ms", Warn: "@100:~", Crit: "2000", Max: float(5000)}.Normalized()
+		assert.DeepEqual(t, d, PerfDatum{Label: "time", Value: 1.5, UOM: "s", Warn: "@0.1:~", Crit: "2", Max: float(5)})
+	})
+	t.Run("Sizes are converted to bytes", func(t *testing.T) {
+		d := PerfDatum{Label: "mem", Value: 2, UOM: "KB"}.Normalized()
+		assert.Equal(t, d.Value, 2048.0)
+		assert.Equal(t, d.UOM, "B")
+	})
+	t.Run("Other units stay unchanged", func(t *testing.T) {
+		d := PerfDatum{Label: "used", Value: 80, UOM: "%", Warn: "90"}
+		assert.DeepEqual(t, d.Normalized(), d)
+	})
+}
+
+func Test_FormatPerfData(t *testing.T) {
+	t.Run("Formatting omits trailing empty fields and quotes labels", func(t *testing.T) {
+		formatted := FormatPerfData([]PerfDatum{
+			{Label: "load1", Value: 0.5, Warn: "5", Crit: "10", Min: float(0)},
+			{Label: "it's", Value: math.NaN()},
+			{Label: "time", Value: 12, UOM: "ms", Max: float(100)},
+		})
+		assert.Equal(t, formatted, "load1=0.5;5;10;0 'it''s'=U time=12ms;;;;100")
+	})
+}
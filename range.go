@@ -0,0 +1,127 @@
+package thruk
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Range is a threshold in the range format of the Nagios plugin
+// guidelines. A value outside of Start and End raises an alert, or inside
+// if Inside is set. Open ends are infinite.
+type Range struct {
+	Start  float64
+	End    float64
+	Inside bool
+}
+
+// ParseRange parses ranges like "10", "10:", "~:10", "10:20" and "@10:20".
+func ParseRange(text string) (Range, error) {
+	r := Range{}
+	definition := strings.TrimSpace(text)
+	if strings.HasPrefix(definition, "@") {
+		r.Inside = true
+		definition = definition[1:]
+	}
+	if definition == "" {
+		return r, fmt.Errorf("invalid range %q", text)
+	}
+
+	start, end := "0", definition
+	if colon := strings.IndexByte(definition, ':'); colon >= 0 {
+		start, end = definition[:colon], definition[colon+1:]
+	}
+	var err error
+	switch start {
+	case "~":
+		r.Start = math.Inf(-1)
+	case "":
+		return r, fmt.Errorf("invalid range %q", text)
+	default:
+		if r.Start, err = parsePerfNumber(start); err != nil {
+			return r, fmt.Errorf("invalid range %q", text)
+		}
+	}
+	if end == "" {
+		r.End = math.Inf(1)
+	} else if r.End, err = parsePerfNumber(end); err != nil {
+		return r, fmt.Errorf("invalid range %q", text)
+	}
+	if r.Start > r.End {
+		return r, fmt.Errorf("invalid range %q: start is greater than end", text)
+	}
+	return r, nil
+}
+
+// String returns the shortest notation of the range.
+func (r Range) String() string {
+	var text string
+	switch {
+	case r.Start == 0 && !math.IsInf(r.End, 1):
+		text = formatPerfNumber(r.End)
+	case math.IsInf(r.End, 1):
+		text = formatRangeStart(r.Start) + ":"
+	default:
+		text = formatRangeStart(r.Start) + ":" + formatPerfNumber(r.End)
+	}
+	if r.Inside {
+		return "@" + text
+	}
+	return text
+}
+
+func formatRangeStart(start float64) string {
+	if math.IsInf(start, -1) {
+		return "~"
+	}
+	return formatPerfNumber(start)
+}
+
+// Alert reports whether value raises an alert. Both ends belong to the
+// range.
+func (r Range) Alert(value float64) bool {
+	inside := value >= r.Start && value <= r.End
+	return inside == r.Inside
+}
+
+// WarnRange returns the warning threshold, ok is false when there is none.
+func (d PerfDatum) WarnRange() (r Range, ok bool, err error) {
+	return optionalRange(d.Warn)
+}
+
+// CritRange returns the critical threshold, ok is false when there is none.
+func (d PerfDatum) CritRange() (r Range, ok bool, err error) {
+	return optionalRange(d.Crit)
+}
+
+func optionalRange(text string) (Range, bool, error) {
+	if text == "" {
+		return Range{}, false, nil
+	}
+	r, err := ParseRange(text)
+	return r, err == nil, err
+}
+
+// State recomputes the state of the datum from its value and thresholds:
+// CRITICAL or WARNING if the value raises an alert in the respective range,
+// OK otherwise. Unknown values are UNKNOWN.
+func (d PerfDatum) State() (int, error) {
+	if math.IsNaN(d.Value) {
+		return StateUnknown, nil
+	}
+	crit, ok, err := d.CritRange()
+	if err != nil {
+		return StateUnknown, err
+	}
+	if ok && crit.Alert(d.Value) {
+		return StateCritical, nil
+	}
+	warn, ok, err := d.WarnRange()
+	if err != nil {
+		return StateUnknown, err
+	}
+	if ok && warn.Alert(d.Value) {
+		return StateWarning, nil
+	}
+	return StateOK, nil
+}
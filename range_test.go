@@ -0,0 +1,86 @@
+package thruk
+
+import (
+	"gotest.tools/assert"
+	"math"
+	"testing"
+)
+
+func Test_ParseRange(t *testing.T) {
+	t.Run("Range notations from the plugin guidelines", func(t *testing.T) {
+		for text, expected := range map[string]Range{
+			"10":      {Start: 0, End: 10},
+			"10:":     {Start: 10, End: math.Inf(1)},
+			"~:10":    {Start: math.Inf(-1), End: 10},
+			"10:20":   {Start: 10, End: 20},
+			"@10:20":  {Start: 10, End: 20, Inside: true},
+			"-5.5:0":  {Start: -5.5, End: 0},
+			"@~:":     {Start: math.Inf(-1), End: math.Inf(1), Inside: true},
+			" 0,5:1 ": {Start: 0.5, End: 1},
+		} {
+			r, err := ParseRange(text)
+			assert.NilError(t, err, text)
+			assert.DeepEqual(t, r, expected)
+		}
+	})
+	t.Run("Invalid ranges return error", func(t *testing.T) {
+		for _, text := range []string{"", "@", ":10", "abc", "20:10", "1:2:3", "~"} {
+			_, err := ParseRange(text)
+			assert.Assert(t, err != nil, text)
+		}
+	})
+	t.Run("String returns the shortest notation", func(t *testing.T) {
+		for text, expected := range map[string]string{"10": "10", "0:10": "10", "10:": "10:", "~:10": "~:10", "@10:20": "@10:20", "~:": "~:"} {
+			r, err := ParseRange(text)
+			assert.NilError(t, err)
+			assert.Equal(t, r.String(), expected)
+		}
+	})
+}
+
+func Test_Range_Alert(t *testing.T) {
+	alerts := func(text string, value float64) bool {
+		r, err := ParseRange(text)
+		assert.NilError(t, err)
+		return r.Alert(value)
+	}
+
+	t.Run("Values outside of the range alert", func(t *testing.T) {
+		assert.Assert(t, alerts("10", -1))
+		assert.Assert(t, !alerts("10", 0))
+		assert.Assert(t, !alerts("10", 10))
+		assert.Assert(t, alerts("10", 10.1))
+		assert.Assert(t, alerts("10:", 9))
+		assert.Assert(t, !alerts("10:", 1e9))
+		assert.Assert(t, !alerts("~:10", -1e9))
+		assert.Assert(t, alerts("10:20", 21))
+	})
+	t.Run("Values inside of an inverted range alert", func(t *testing.T) {
+		assert.Assert(t, alerts("@10:20", 10))
+		assert.Assert(t, alerts("@10:20", 20))
+		assert.Assert(t, !alerts("@10:20", 21))
+	})
+}
+
+func Test_PerfDatum_State(t *testing.T) {
+	t.Run("State is recomputed from the thresholds", func(t *testing.T) {
+		for value, expected := range map[float64]int{50: StateOK, 85: StateWarning, 95: StateCritical} {
+			state, err := PerfDatum{Label: "used", Value: value, Warn: "80", Crit: "90"}.State()
+			assert.NilError(t, err)
+			assert.Equal(t, state, expected)
+		}
+	})
+	t.Run("Missing thresholds are OK and unknown values UNKNOWN", func(t *testing.T) {
+		state, err := PerfDatum{Label: "x", Value: 1e9}.State()
+		assert.NilError(t, err)
+		assert.Equal(t, state, StateOK)
+
+		state, err = PerfDatum{Label: "x", Value: math.NaN(), Crit: "1"}.State()
+		assert.NilError(t, err)
+		assert.Equal(t, state, StateUnknown)
+	})
+	t.Run("Invalid thresholds return error", func(t *testing.T) {
+		_, err := PerfDatum{Label: "x", Value: 1, Warn: "10:5"}.State()
+		assert.Error(t, err, `invalid range "10:5": start is greater than end`)
+	})
+}
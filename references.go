@@ -0,0 +1,576 @@
+package thruk
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+var ErrorRequiredReference = errors.New("[ERROR] object is required by other objects")
+
+// Reference is an object that refers to another object through one of its
+// attributes.
+type Reference struct {
+	ID        string
+	Type      string
+	Name      string
+	Attribute string
+}
+
+type CascadeAction string
+
+const (
+	// CascadeDelete removes the referencing object because it cannot exist
+	// without the deleted one, e.g. a service bound to a single host.
+	CascadeDelete CascadeAction = "delete"
+	// CascadeDetach removes the deleted object from the attribute.
+	CascadeDetach CascadeAction = "detach"
+)
+
+type CascadeChange struct {
+	Reference
+	Action CascadeAction
+}
+
+type CascadeOptions struct {
+	// DryRun only reports the changes DeleteCascade would stage.
+	DryRun bool
+}
+
+// nameAttributes maps object types to the attribute other objects use to
+// refer to them.
+var nameAttributes = map[string]string{
+	"host":         "host_name",
+	"hostgroup":    "hostgroup_name",
+	"service":      "service_description",
+	"servicegroup": "servicegroup_name",
+	"command":      "command_name",
+	"timeperiod":   "timeperiod_name",
+	"contact":      "contact_name",
+	"contactgroup": "contactgroup_name",
+}
+
+// requiredAttributes are the attributes the configuration check refuses
+// objects without, either set on the object or inherited from a template,
+// that a cascade could remove.
+var requiredAttributes = map[string][]string{
+	"host":    {"max_check_attempts"},
+	"service": {"service_description", "check_command", "max_check_attempts"},
+	"contact": {"host_notification_commands", "service_notification_commands", "host_notification_period", "service_notification_period"},
+}
+
+type referenceKind int
+
+const (
+	// a list of names, entries may be negated with a leading "!"
+	referenceList referenceKind = iota
+	// a single name
+	referenceScalar
+	// a command name followed by "!"-separated arguments, or a list of those
+	referenceCommand
+	// servicegroup members, a flat list of host,service pairs
+	referenceMemberPairs
+	// a list of service descriptions qualified by the host list in companion
+	referenceService
+)
+
+type referenceRule struct {
+	target    string
+	types     []string
+	attribute string
+	kind      referenceKind
+	// owner marks references the referencing object cannot exist without.
+	// It is deleted once attribute and companion are both empty.
+	owner     bool
+	companion string
+}
+
+var referenceRules = []referenceRule{
+	{target: "host", types: []string{"service"}, attribute: "host_name", owner: true, companion: "hostgroup_name"},
+	{target: "host", types: []string{"host"}, attribute: "parents"},
+	{target: "host", types: []string{"hostgroup"}, attribute: "members"},
+	{target: "host", types: []string{"hostdependency", "hostescalation", "servicedependency", "serviceescalation"}, attribute: "host_name", owner: true, companion: "hostgroup_name"},
+	{target: "host", types: []string{"hostdependency", "servicedependency"}, attribute: "dependent_host_name", owner: true, companion: "dependent_hostgroup_name"},
+	{target: "host", types: []string{"servicegroup"}, attribute: "members", kind: referenceMemberPairs},
+
+	{target: "hostgroup", types: []string{"host"}, attribute: "hostgroups"},
+	{target: "hostgroup", types: []string{"hostgroup"}, attribute: "hostgroup_members"},
+	{target: "hostgroup", types: []string{"service", "hostdependency", "hostescalation", "servicedependency", "serviceescalation"}, attribute: "hostgroup_name", owner: true, companion: "host_name"},
+	{target: "hostgroup", types: []string{"hostdependency", "servicedependency"}, attribute: "dependent_hostgroup_name", owner: true, companion: "dependent_host_name"},
+
+	{target: "service", types: []string{"servicegroup"}, attribute: "members", kind: referenceMemberPairs},
+	{target: "service", types: []string{"servicedependency", "serviceescalation"}, attribute: "service_description", kind: referenceService, owner: true, companion: "host_name"},
+	{target: "service", types: []string{"servicedependency"}, attribute: "dependent_service_description", kind: referenceService, owner: true, companion: "dependent_host_name"},
+
+	{target: "servicegroup", types: []string{"service"}, attribute: "servicegroups"},
+	{target: "servicegroup", types: []string{"servicegroup"}, attribute: "servicegroup_members"},
+	{target: "servicegroup", types: []string{"servicedependency", "serviceescalation"}, attribute: "servicegroup_name", owner: true, companion: "host_name"},
+	{target: "servicegroup", types: []string{"servicedependency"}, attribute: "dependent_servicegroup_name", owner: true, companion: "dependent_host_name"},
+
+	{target: "command", types: []string{"host", "service"}, attribute: "check_command", kind: referenceCommand},
+	{target: "command", types: []string{"host", "service"}, attribute: "event_handler", kind: referenceCommand},
+	{target: "command", types: []string{"contact"}, attribute: "host_notification_commands", kind: referenceCommand},
+	{target: "command", types: []string{"contact"}, attribute: "service_notification_commands", kind: referenceCommand},
+
+	{target: "timeperiod", types: []string{"host", "service"}, attribute: "check_period", kind: referenceScalar},
+	{target: "timeperiod", types: []string{"host", "service"}, attribute: "notification_period", kind: referenceScalar},
+	{target: "timeperiod", types: []string{"contact"}, attribute: "host_notification_period", kind: referenceScalar},
+	{target: "timeperiod", types: []string{"contact"}, attribute: "service_notification_period", kind: referenceScalar},
+	{target: "timeperiod", types: []string{"hostescalation", "serviceescalation"}, attribute: "escalation_period", kind: referenceScalar},
+	{target: "timeperiod", types: []string{"hostdependency", "servicedependency"}, attribute: "dependency_period", kind: referenceScalar},
+	{target: "timeperiod", types: []string{"timeperiod"}, attribute: "exclude"},
+
+	{target: "contact", types: []string{"host", "service", "hostescalation", "serviceescalation"}, attribute: "contacts"},
+	{target: "contact", types: []string{"contactgroup"}, attribute: "members"},
+
+	{target: "contactgroup", types: []string{"host", "service", "hostescalation", "serviceescalation"}, attribute: "contact_groups"},
+	{target: "contactgroup", types: []string{"contact"}, attribute: "contactgroups"},
+	{target: "contactgroup", types: []string{"contactgroup"}, attribute: "contactgroup_members"},
+}
+
+type rawObject map[string]interface{}
+
+func (o rawObject) id() string {
+	return o.str(":ID")
+}
+
+func (o rawObject) objectType() string {
+	return o.str(":TYPE")
+}
+
+func (o rawObject) str(attribute string) string {
+	value, _ := o[attribute].(string)
+	return value
+}
+
+// list returns a list attribute, accepting both JSON arrays and the comma
+// separated form used in the object files.
+func (o rawObject) list(attribute string) []string {
+	switch value := o[attribute].(type) {
+	case string:
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func (o rawObject) setList(attribute string, values []string) {
+	if len(values) == 0 {
+		delete(o, attribute)
+		return
+	}
+	list := make([]interface{}, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+	o[attribute] = list
+}
+
+// name returns a human readable identifier of the object.
+func (o rawObject) name() string {
+	if o.objectType() == "service" {
+		description := o.str("service_description")
+		if description == "" {
+			description = o.str("name")
+		}
+		return strings.Join(o.list("host_name"), ",") + "/" + description
+	}
+	if attribute, ok := nameAttributes[o.objectType()]; ok && o.str(attribute) != "" {
+		return o.str(attribute)
+	}
+	return o.str("name")
+}
+
+func (o rawObject) reference(attribute string) Reference {
+	return Reference{ID: o.id(), Type: o.objectType(), Name: o.name(), Attribute: attribute}
+}
+
+func (t Thruk) listRawConfigObjects(filter url.Values) ([]rawObject, error) {
+	var objects []rawObject
+	err := t.findConfigObjects(filter, &objects)
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// referenceTarget holds the names an object can be referred to by.
+type referenceTarget struct {
+	objectType string
+	name       string
+	template   string
+	// hosts the target service is bound to
+	hosts []string
+}
+
+func newReferenceTarget(object rawObject) referenceTarget {
+	target := referenceTarget{
+		objectType: object.objectType(),
+		name:       object.str(nameAttributes[object.objectType()]),
+		template:   object.str("name"),
+	}
+	if target.objectType == "service" {
+		target.hosts = object.list("host_name")
+	}
+	return target
+}
+
+func isName(value, name string) bool {
+	return name != "" && (value == name || value == "!"+name)
+}
+
+func commandName(value string) string {
+	return strings.SplitN(value, "!", 2)[0]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r referenceRule) appliesTo(objectType string) bool {
+	return contains(r.types, objectType)
+}
+
+// references reports whether object refers to target through the rule.
+func (r referenceRule) references(object rawObject, target referenceTarget) bool {
+	if target.name == "" {
+		return false
+	}
+	switch r.kind {
+	case referenceScalar:
+		return object.str(r.attribute) == target.name
+	case referenceCommand:
+		for _, value := range object.list(r.attribute) {
+			if commandName(value) == target.name {
+				return true
+			}
+		}
+		return false
+	case referenceMemberPairs:
+		members := object.list(r.attribute)
+		for i := 0; i+1 < len(members); i += 2 {
+			if target.matchesPair(members[i], members[i+1]) {
+				return true
+			}
+		}
+		return false
+	case referenceService:
+		if !containsAny(object.list(r.companion), target.hosts) {
+			return false
+		}
+	}
+	for _, value := range object.list(r.attribute) {
+		if isName(value, target.name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (target referenceTarget) matchesPair(host, service string) bool {
+	if target.objectType == "service" {
+		return service == target.name && contains(target.hosts, host)
+	}
+	return host == target.name
+}
+
+func containsAny(values, candidates []string) bool {
+	for _, candidate := range candidates {
+		if contains(values, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// detach removes target from the attribute of object and reports whether
+// the object has to be deleted as a consequence.
+func (r referenceRule) detach(object rawObject, target referenceTarget) bool {
+	switch r.kind {
+	case referenceScalar:
+		delete(object, r.attribute)
+		return false
+	case referenceCommand:
+		if _, ok := object[r.attribute].(string); ok {
+			delete(object, r.attribute)
+			return false
+		}
+		var kept []string
+		for _, value := range object.list(r.attribute) {
+			if commandName(value) != target.name {
+				kept = append(kept, value)
+			}
+		}
+		object.setList(r.attribute, kept)
+		return false
+	case referenceMemberPairs:
+		members := object.list(r.attribute)
+		var kept []string
+		for i := 0; i+1 < len(members); i += 2 {
+			if !target.matchesPair(members[i], members[i+1]) {
+				kept = append(kept, members[i], members[i+1])
+			}
+		}
+		object.setList(r.attribute, kept)
+		return false
+	case referenceService:
+		// the description applies to every host in companion, so the
+		// object cannot be narrowed down to the remaining services
+		return true
+	}
+	var kept []string
+	for _, value := range object.list(r.attribute) {
+		if !isName(value, target.name) {
+			kept = append(kept, value)
+		}
+	}
+	object.setList(r.attribute, kept)
+	return r.owner && len(kept) == 0 && len(object.list(r.companion)) == 0
+}
+
+func (o rawObject) isTemplate() bool {
+	return fmt.Sprint(o["register"]) == "0"
+}
+
+func (o rawObject) has(attribute string) bool {
+	switch value := o[attribute].(type) {
+	case nil:
+		return false
+	case string:
+		return value != "" && value != "null"
+	case []interface{}:
+		return len(value) > 0
+	}
+	return true
+}
+
+// templateIndex finds templates by type and name.
+type templateIndex map[string][]rawObject
+
+func newTemplateIndex(objects []rawObject) templateIndex {
+	index := templateIndex{}
+	for _, object := range objects {
+		if name := object.str("name"); name != "" {
+			key := object.objectType() + "/" + name
+			index[key] = append(index[key], object)
+		}
+	}
+	return index
+}
+
+// missingRequired returns the required attributes object neither sets nor
+// inherits from a template that is not deleted.
+func (index templateIndex) missingRequired(object rawObject, deleted map[string]bool) []string {
+	if object.isTemplate() {
+		return nil
+	}
+	var missing []string
+	for _, attribute := range requiredAttributes[object.objectType()] {
+		if !index.inherits(object, attribute, deleted, map[string]bool{}) {
+			missing = append(missing, attribute)
+		}
+	}
+	return missing
+}
+
+func (index templateIndex) inherits(object rawObject, attribute string, deleted, seen map[string]bool) bool {
+	if object.has(attribute) {
+		return true
+	}
+	seen[object.id()] = true
+	for _, name := range object.list("use") {
+		for _, template := range index[object.objectType()+"/"+name] {
+			if deleted[template.id()] || seen[template.id()] {
+				continue
+			}
+			if index.inherits(template, attribute, deleted, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type referenceMatch struct {
+	object rawObject
+	rule   referenceRule
+	target referenceTarget
+}
+
+// referencesTo returns every reference from objects to target, including
+// objects that inherit from target when it is a template.
+func referencesTo(objects []rawObject, target referenceTarget, skip func(rawObject) bool) []referenceMatch {
+	var found []referenceMatch
+	for _, object := range objects {
+		if skip(object) {
+			continue
+		}
+		for _, rule := range referenceRules {
+			if rule.target != target.objectType || !rule.appliesTo(object.objectType()) {
+				continue
+			}
+			if rule.references(object, target) {
+				found = append(found, referenceMatch{object: object, rule: rule, target: target})
+			}
+		}
+		if target.template != "" && object.objectType() == target.objectType {
+			rule := referenceRule{target: target.objectType, types: []string{target.objectType}, attribute: "use"}
+			template := referenceTarget{objectType: target.objectType, name: target.template}
+			if rule.references(object, template) {
+				found = append(found, referenceMatch{object: object, rule: rule, target: template})
+			}
+		}
+	}
+	return found
+}
+
+func (t Thruk) loadReferenceGraph(id string) (rawObject, []rawObject, error) {
+	if id == "" {
+		return nil, nil, ErrorInvalidInput
+	}
+	objects, err := t.listRawConfigObjects(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, object := range objects {
+		if object.id() == id {
+			return object, objects, nil
+		}
+	}
+	return nil, nil, ErrorObjectNotFound
+}
+
+// FindReferences lists every object referring to the object with the given
+// ID: services bound to a host, group members, dependencies, escalations,
+// commands, timeperiods, contacts and objects using it as a template.
+func (t Thruk) FindReferences(id string) ([]Reference, error) {
+	object, objects, err := t.loadReferenceGraph(id)
+	if err != nil {
+		return nil, err
+	}
+	var references []Reference
+	skip := func(o rawObject) bool { return o.id() == id }
+	for _, found := range referencesTo(objects, newReferenceTarget(object), skip) {
+		references = append(references, found.object.reference(found.rule.attribute))
+	}
+	return references, nil
+}
+
+// DeleteCascade deletes an object together with the objects that cannot
+// exist without it and detaches it from all other references, so the
+// remaining configuration still passes CheckConfig. Objects that would lose
+// a required attribute, like the check_command of a service, the last
+// notification command of a contact or a template supplying them, are not
+// changed: DeleteCascade returns ErrorRequiredReference naming them and
+// stages nothing. Changes are staged like any other change and returned in
+// the order they were found.
+func (t Thruk) DeleteCascade(id string, opts CascadeOptions) ([]CascadeChange, error) {
+	object, objects, err := t.loadReferenceGraph(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// attributes missing already are not the cascade's business
+	templates := newTemplateIndex(objects)
+	missingBefore := map[string][]string{}
+	for _, o := range objects {
+		missingBefore[o.id()] = templates.missingRequired(o, nil)
+	}
+
+	deleted := map[string]bool{}
+	patched := map[string]map[string]bool{}
+	var changes []CascadeChange
+	var remove func(object rawObject)
+	remove = func(object rawObject) {
+		deleted[object.id()] = true
+		skip := func(o rawObject) bool { return deleted[o.id()] }
+		for _, found := range referencesTo(objects, newReferenceTarget(object), skip) {
+			if deleted[found.object.id()] {
+				continue
+			}
+			reference := found.object.reference(found.rule.attribute)
+			if found.rule.detach(found.object, found.target) {
+				changes = append(changes, CascadeChange{Reference: reference, Action: CascadeDelete})
+				remove(found.object)
+				continue
+			}
+			changes = append(changes, CascadeChange{Reference: reference, Action: CascadeDetach})
+			if patched[found.object.id()] == nil {
+				patched[found.object.id()] = map[string]bool{}
+			}
+			patched[found.object.id()][found.rule.attribute] = true
+		}
+	}
+	remove(object)
+
+	// an object detached first may have been deleted through another reference
+	kept := changes[:0]
+	for _, change := range changes {
+		if change.Action == CascadeDelete || !deleted[change.ID] {
+			kept = append(kept, change)
+		}
+	}
+	changes = kept
+
+	var blocking []string
+	for _, o := range objects {
+		if deleted[o.id()] {
+			continue
+		}
+		for _, attribute := range templates.missingRequired(o, deleted) {
+			if !contains(missingBefore[o.id()], attribute) {
+				blocking = append(blocking, o.objectType()+" "+o.name()+" ("+attribute+")")
+			}
+		}
+	}
+	if len(blocking) > 0 {
+		sort.Strings(blocking)
+		return nil, fmt.Errorf("%w: %s", ErrorRequiredReference, strings.Join(blocking, ", "))
+	}
+
+	if opts.DryRun {
+		return changes, nil
+	}
+	for _, object := range objects {
+		attributes, ok := patched[object.id()]
+		if !ok || deleted[object.id()] {
+			continue
+		}
+		patch := map[string]interface{}{}
+		for attribute := range attributes {
+			patch[attribute] = object[attribute]
+		}
+		if err := t.PatchConfigObject(object.id(), patch); err != nil {
+			return changes, err
+		}
+	}
+	for _, change := range changes {
+		if change.Action != CascadeDelete {
+			continue
+		}
+		if err := t.DeleteConfigObject(change.ID); err != nil {
+			return changes, err
+		}
+	}
+	return changes, t.DeleteConfigObject(id)
+}
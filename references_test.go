@@ -0,0 +1,192 @@
+package thruk
+
+import (
+	"errors"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func createHostWithService(t *testing.T, thruk *Thruk, hostName string) (string, string) {
+	t.Helper()
+	hostID, err := thruk.CreateHost(Host{
+		FILE:     "references.cfg",
+		TYPE:     "host",
+		HostName: hostName,
+		Address:  "127.0.0.1",
+		Use:      []string{"generic-host"},
+	})
+	assert.NilError(t, err)
+	serviceID, err := thruk.CreateService(Service{
+		FILE:               "references.cfg",
+		TYPE:               "service",
+		HostName:           []string{hostName},
+		ServiceDescription: "ping",
+		CheckCommand:       "check_ping!100,20%!500,60%",
+		Use:                []string{"generic-service"},
+	})
+	assert.NilError(t, err)
+	return hostID, serviceID
+}
+
+func Test_thruk_client_References(t *testing.T) {
+	t.Run("Find references of empty id returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.FindReferences("")
+		assert.Error(t, err, "[ERROR] invalid input")
+	})
+	t.Run("Find references lists services and groups pointing at a host", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		hostID, serviceID := createHostWithService(t, thruk, "ref-host")
+		groupID, err := thruk.CreateHostgroup(Hostgroup{
+			FILE:          "references.cfg",
+			TYPE:          "hostgroup",
+			HostgroupName: "ref-group",
+			Members:       []string{"ref-host"},
+		})
+		assert.NilError(t, err)
+
+		references, err := thruk.FindReferences(hostID)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, references, []Reference{
+			{ID: serviceID, Type: "service", Name: "ref-host/ping", Attribute: "host_name"},
+			{ID: groupID, Type: "hostgroup", Name: "ref-group", Attribute: "members"},
+		})
+	})
+	t.Run("Delete cascade dry run does not change anything", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		hostID, serviceID := createHostWithService(t, thruk, "dry-host")
+
+		changes, err := thruk.DeleteCascade(hostID, CascadeOptions{DryRun: true})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, changes, []CascadeChange{
+			{Reference: Reference{ID: serviceID, Type: "service", Name: "dry-host/ping", Attribute: "host_name"}, Action: CascadeDelete},
+		})
+		_, err = thruk.GetHost(hostID)
+		assert.NilError(t, err)
+		_, err = thruk.GetService(serviceID)
+		assert.NilError(t, err)
+	})
+	t.Run("Delete cascade removes dependents and keeps the configuration valid", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		hostID, serviceID := createHostWithService(t, thruk, "cascade-host")
+		otherID, err := thruk.CreateHost(Host{
+			FILE:     "references.cfg",
+			TYPE:     "host",
+			HostName: "cascade-child",
+			Address:  "127.0.0.1",
+			Parents:  []string{"cascade-host"},
+			Use:      []string{"generic-host"},
+		})
+		assert.NilError(t, err)
+
+		_, err = thruk.DeleteCascade(hostID, CascadeOptions{})
+		assert.NilError(t, err)
+		assert.NilError(t, thruk.SaveConfigs())
+
+		_, err = thruk.GetService(serviceID)
+		assert.Error(t, err, "[ERROR] Object not found")
+		child, err := thruk.GetHost(otherID)
+		assert.NilError(t, err)
+		assert.Equal(t, len(child.Parents), 0)
+		assert.Assert(t, thruk.CheckConfig())
+	})
+	t.Run("Delete cascade refuses to remove the check command of a service", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		commandID, err := thruk.CreateCommand(Command{
+			FILE:        "references.cfg",
+			TYPE:        "command",
+			CommandName: "check_cascade",
+			CommandLine: "$USER1$/check_dummy 0",
+		})
+		assert.NilError(t, err)
+		_, err = thruk.CreateHost(Host{
+			FILE:     "references.cfg",
+			TYPE:     "host",
+			HostName: "command-host",
+			Address:  "127.0.0.1",
+			Use:      []string{"generic-host"},
+		})
+		assert.NilError(t, err)
+		serviceID, err := thruk.CreateService(Service{
+			FILE:               "references.cfg",
+			TYPE:               "service",
+			HostName:           []string{"command-host"},
+			ServiceDescription: "cascade",
+			CheckCommand:       "check_cascade",
+			Use:                []string{"generic-service"},
+		})
+		assert.NilError(t, err)
+
+		_, err = thruk.DeleteCascade(commandID, CascadeOptions{})
+		assert.Assert(t, errors.Is(err, ErrorRequiredReference), err)
+		service, err := thruk.GetService(serviceID)
+		assert.NilError(t, err)
+		assert.Equal(t, service.CheckCommand, "check_cascade")
+		_, err = thruk.GetCommand(commandID)
+		assert.NilError(t, err)
+	})
+}
+
+func Test_DeleteCascade_RequiredReferences(t *testing.T) {
+	objects := []map[string]interface{}{
+		{":ID": "c1", ":TYPE": "command", "command_name": "check_foo"},
+		{":ID": "c2", ":TYPE": "command", "command_name": "notify_foo"},
+		{":ID": "h1", ":TYPE": "host", "host_name": "web01", "check_command": "check_foo", "max_check_attempts": "3"},
+		{":ID": "t1", ":TYPE": "service", "name": "foo-service", "register": "0", "check_command": "check_foo!1", "max_check_attempts": "3"},
+		{":ID": "s1", ":TYPE": "service", "host_name": []interface{}{"web01"}, "service_description": "foo", "use": []interface{}{"foo-service"}},
+		{":ID": "s2", ":TYPE": "service", "host_name": []interface{}{"web01"}, "service_description": "bar", "check_command": "check_bar", "max_check_attempts": "3"},
+		{":ID": "p1", ":TYPE": "contact", "contact_name": "ops", "host_notification_commands": []interface{}{"notify_foo"}, "service_notification_commands": []interface{}{"notify_foo", "notify_mail"}, "host_notification_period": "24x7", "service_notification_period": "24x7"},
+	}
+	startServer := func(objects []map[string]interface{}) (*Thruk, *fakeConfig, func()) {
+		config := &fakeConfig{objects: objects}
+		server := httptest.NewServer(config)
+		return NewThruk(server.URL, "site", "user", "pass", false), config, server.Close
+	}
+
+	t.Run("A command used as check command of a service is not deleted", func(t *testing.T) {
+		thruk, config, closeServer := startServer(objects)
+		defer closeServer()
+
+		for _, dryRun := range []bool{true, false} {
+			config.requests = 0
+			_, err := thruk.DeleteCascade("t1", CascadeOptions{DryRun: dryRun})
+			assert.Assert(t, errors.Is(err, ErrorRequiredReference), err)
+			assert.ErrorContains(t, err, "service web01/foo (check_command)")
+			assert.ErrorContains(t, err, "service web01/foo (max_check_attempts)")
+			assert.Equal(t, config.requests, 1)
+		}
+	})
+
+	t.Run("The last notification command of a contact is not removed", func(t *testing.T) {
+		thruk, _, closeServer := startServer(objects)
+		defer closeServer()
+
+		_, err := thruk.DeleteCascade("c2", CascadeOptions{DryRun: true})
+		assert.Error(t, err, "[ERROR] object is required by other objects: contact ops (host_notification_commands)")
+	})
+
+	t.Run("A check command inherited from a template is required too", func(t *testing.T) {
+		thruk, _, closeServer := startServer(objects)
+		defer closeServer()
+
+		_, err := thruk.DeleteCascade("c1", CascadeOptions{DryRun: true})
+		assert.Error(t, err, "[ERROR] object is required by other objects: service web01/foo (check_command)")
+	})
+
+	t.Run("Optional commands are detached", func(t *testing.T) {
+		thruk, _, closeServer := startServer([]map[string]interface{}{objects[0], objects[2]})
+		defer closeServer()
+
+		changes, err := thruk.DeleteCascade("c1", CascadeOptions{DryRun: true})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, changes, []CascadeChange{
+			{Reference: Reference{ID: "h1", Type: "host", Name: "web01", Attribute: "check_command"}, Action: CascadeDetach},
+		})
+	})
+}
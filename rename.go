@@ -0,0 +1,122 @@
+package thruk
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrorNameTaken = errors.New("[ERROR] an object of this type already uses the new name")
+
+// rename replaces target with newName in the attribute of object.
+func (r referenceRule) rename(object rawObject, target referenceTarget, newName string) {
+	switch r.kind {
+	case referenceScalar:
+		object[r.attribute] = newName
+		return
+	case referenceCommand:
+		renameCommand := func(value string) string {
+			if commandName(value) != target.name {
+				return value
+			}
+			return newName + strings.TrimPrefix(value, target.name)
+		}
+		if value, ok := object[r.attribute].(string); ok {
+			object[r.attribute] = renameCommand(value)
+			return
+		}
+		values := object.list(r.attribute)
+		for i, value := range values {
+			values[i] = renameCommand(value)
+		}
+		object.setList(r.attribute, values)
+		return
+	case referenceMemberPairs:
+		members := object.list(r.attribute)
+		for i := 0; i+1 < len(members); i += 2 {
+			if !target.matchesPair(members[i], members[i+1]) {
+				continue
+			}
+			if target.objectType == "service" {
+				members[i+1] = newName
+			} else {
+				members[i] = newName
+			}
+		}
+		object.setList(r.attribute, members)
+		return
+	}
+	values := object.list(r.attribute)
+	for i, value := range values {
+		switch value {
+		case target.name:
+			values[i] = newName
+		case "!" + target.name:
+			values[i] = "!" + newName
+		}
+	}
+	object.setList(r.attribute, values)
+}
+
+// RenameObject changes the name of a host, service, group, command,
+// timeperiod, contact or template and rewrites every reference to it, e.g.
+// host_name, parents, group members, the check_command prefix and use.
+// All changes are staged together; the renamed object and every rewritten
+// object are returned.
+func (t Thruk) RenameObject(id, newName string) ([]Reference, error) {
+	if newName == "" {
+		return nil, ErrorInvalidInput
+	}
+	object, objects, err := t.loadReferenceGraph(id)
+	if err != nil {
+		return nil, err
+	}
+	target := newReferenceTarget(object)
+	attribute := nameAttributes[target.objectType]
+	if target.name == "" {
+		// templates are only known by their name
+		attribute = "name"
+		target.name, target.template = target.template, ""
+	}
+	if target.name == "" {
+		return nil, ErrorInvalidInput
+	}
+	for _, other := range objects {
+		if other.objectType() == target.objectType && other.id() != id && other.str(attribute) == newName {
+			if target.objectType != "service" || containsAny(other.list("host_name"), target.hosts) {
+				return nil, ErrorNameTaken
+			}
+		}
+	}
+
+	if attribute == "name" {
+		target = referenceTarget{objectType: target.objectType, template: target.name}
+	} else {
+		target.template = ""
+	}
+	found := referencesTo(objects, target, func(o rawObject) bool { return o.id() == id })
+
+	object[attribute] = newName
+	touched := []Reference{object.reference(attribute)}
+	patches := map[string]map[string]interface{}{id: {attribute: newName}}
+	var order []string
+	for _, match := range found {
+		match.rule.rename(match.object, match.target, newName)
+		objectID := match.object.id()
+		if patches[objectID] == nil {
+			patches[objectID] = map[string]interface{}{}
+			order = append(order, objectID)
+		}
+		patches[objectID][match.rule.attribute] = match.object[match.rule.attribute]
+		touched = append(touched, match.object.reference(match.rule.attribute))
+	}
+
+	if err := t.PatchConfigObject(id, patches[id]); err != nil {
+		return nil, err
+	}
+	for _, objectID := range order {
+		if err := t.PatchConfigObject(objectID, patches[objectID]); err != nil {
+			return touched, err
+		}
+	}
+	return touched, nil
+}
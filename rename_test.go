@@ -0,0 +1,85 @@
+package thruk
+
+import (
+	"gotest.tools/assert"
+	"testing"
+)
+
+func Test_thruk_client_RenameObject(t *testing.T) {
+	t.Run("Rename object to an empty name returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.RenameObject("8e4f0", "")
+		assert.Error(t, err, "[ERROR] invalid input")
+	})
+	t.Run("Rename host rewrites services, parents and group members", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		hostID, serviceID := createHostWithService(t, thruk, "rename-host")
+		childID, err := thruk.CreateHost(Host{
+			FILE:     "references.cfg",
+			TYPE:     "host",
+			HostName: "rename-child",
+			Parents:  []string{"rename-host"},
+		})
+		assert.NilError(t, err)
+		groupID, err := thruk.CreateHostgroup(Hostgroup{
+			FILE:          "references.cfg",
+			TYPE:          "hostgroup",
+			HostgroupName: "rename-group",
+			Members:       []string{"rename-host", "rename-child"},
+		})
+		assert.NilError(t, err)
+
+		touched, err := thruk.RenameObject(hostID, "renamed-host")
+		assert.NilError(t, err)
+		assert.Equal(t, len(touched), 4)
+
+		host, err := thruk.GetHost(hostID)
+		assert.NilError(t, err)
+		assert.Equal(t, host.HostName, "renamed-host")
+		service, err := thruk.GetService(serviceID)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, service.HostName, []string{"renamed-host"})
+		child, err := thruk.GetHost(childID)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, child.Parents, []string{"renamed-host"})
+		group, err := thruk.GetHostgroup(groupID)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, group.Members, []string{"renamed-host", "rename-child"})
+	})
+	t.Run("Rename command keeps check_command arguments", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		commandID, err := thruk.CreateCommand(Command{
+			FILE:        "references.cfg",
+			TYPE:        "command",
+			CommandName: "check_rename",
+			CommandLine: "$USER1$/check_ping -H $HOSTADDRESS$ -w $ARG1$",
+		})
+		assert.NilError(t, err)
+		hostID, err := thruk.CreateHost(Host{
+			FILE:         "references.cfg",
+			TYPE:         "host",
+			HostName:     "rename-command-host",
+			CheckCommand: "check_rename!100,20%",
+		})
+		assert.NilError(t, err)
+
+		_, err = thruk.RenameObject(commandID, "check_renamed")
+		assert.NilError(t, err)
+
+		host, err := thruk.GetHost(hostID)
+		assert.NilError(t, err)
+		assert.Equal(t, host.CheckCommand, "check_renamed!100,20%")
+	})
+	t.Run("Rename to a name already in use returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		hostID, _ := createHostWithService(t, thruk, "rename-first")
+		createHostWithService(t, thruk, "rename-second")
+
+		_, err := thruk.RenameObject(hostID, "rename-second")
+		assert.Error(t, err, "[ERROR] an object of this type already uses the new name")
+	})
+}
@@ -0,0 +1,161 @@
+package thruk
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// ReportSchedule is an entry of a report's send_types, e.g. Type "week"
+// with WeekDay "1" sends every monday at Hour:Minute.
+type ReportSchedule struct {
+	Type    string `json:"type"`
+	Hour    int    `json:"hour"`
+	Minute  int    `json:"minute"`
+	WeekDay string `json:"week_day,omitempty"`
+	Day     string `json:"day,omitempty"`
+	Cust    string `json:"cust,omitempty"`
+}
+
+// ReportState is the runtime information Thruk keeps about a report.
+type ReportState struct {
+	IsRunning   int    `json:"is_running,omitempty"`
+	StartTime   int64  `json:"start_time,omitempty"`
+	EndTime     int64  `json:"end_time,omitempty"`
+	ContentType string `json:"ctype,omitempty"`
+	Attachment  string `json:"attachment,omitempty"`
+	Job         string `json:"job,omitempty"`
+}
+
+type Report struct {
+	NR          int              `json:"nr,omitempty"`
+	Name        string           `json:"name"`
+	Description string           `json:"desc,omitempty"`
+	Template    string           `json:"template"`
+	User        string           `json:"user,omitempty"`
+	IsPublic    int              `json:"is_public,omitempty"`
+	To          string           `json:"to,omitempty"`
+	CC          string           `json:"cc,omitempty"`
+	Backends    []string         `json:"backends,omitempty"`
+	Params      ReportParams     `json:"params,omitempty"`
+	SendTypes   []ReportSchedule `json:"send_types,omitempty"`
+	Readonly    int              `json:"readonly,omitempty"`
+	Failed      int              `json:"failed,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	Var         *ReportState     `json:"var,omitempty"`
+}
+
+// ReportParams are the template specific parameters of a report, like
+// "timeperiod", "host", "service" or "sla" for the SLA templates.
+type ReportParams map[string]interface{}
+
+func (t Thruk) reportsURL(nr int, action string) string {
+	URL := "/" + t.SiteName + "/thruk/r/thruk/reports"
+	if nr > 0 {
+		URL += "/" + strconv.Itoa(nr)
+	}
+	if action != "" {
+		URL += "/" + action
+	}
+	return URL
+}
+
+func (t Thruk) ListReports() ([]Report, error) {
+	var reports []Report
+	err := t.getJSON(t.reportsURL(0, ""), &reports)
+	return reports, err
+}
+
+func (t Thruk) GetReport(nr int) (Report, error) {
+	if nr <= 0 {
+		return Report{}, ErrorInvalidInput
+	}
+	var reports []Report
+	if err := t.getJSON(t.reportsURL(nr, ""), &reports); err != nil {
+		return Report{}, err
+	}
+	if len(reports) == 0 {
+		return Report{}, ErrorObjectNotFound
+	}
+	return reports[0], nil
+}
+
+// CreateReport creates a report and returns its number.
+func (t Thruk) CreateReport(report Report) (int, error) {
+	if report.Name == "" || report.Template == "" {
+		return 0, ErrorInvalidInput
+	}
+	report.NR = 0
+	report.Var = nil
+	resp, err := t.sendJSON("POST", t.reportsURL(0, ""), report)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	created := struct {
+		NR int `json:"nr"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return 0, err
+	}
+	if created.NR == 0 {
+		return 0, errors.New("report not created")
+	}
+	return created.NR, nil
+}
+
+// UpdateReport replaces the definition of the report report.NR.
+func (t Thruk) UpdateReport(report Report) error {
+	if report.NR <= 0 {
+		return ErrorNeedID
+	}
+	report.Var = nil
+	resp, err := t.sendJSON("POST", t.reportsURL(report.NR, ""), report)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (t Thruk) DeleteReport(nr int) error {
+	if nr <= 0 {
+		return ErrorNeedID
+	}
+	resp, err := t.sendJSON("DELETE", t.reportsURL(nr, ""), nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// GenerateReport starts generating a report in the background. The report
+// is done when its Var.IsRunning is back to 0.
+func (t Thruk) GenerateReport(nr int) error {
+	if nr <= 0 {
+		return ErrorNeedID
+	}
+	resp, err := t.sendJSON("POST", t.reportsURL(nr, "generate"), nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// DownloadReport writes the last generated report, e.g. a PDF or HTML
+// file, to w and returns its content type.
+func (t Thruk) DownloadReport(nr int, w io.Writer) (string, error) {
+	if nr <= 0 {
+		return "", ErrorNeedID
+	}
+	resp, err := t.sendJSON("GET", t.reportsURL(nr, "report"), nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return "", err
+	}
+	return resp.Header.Get("Content-Type"), nil
+}
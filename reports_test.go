@@ -0,0 +1,75 @@
+package thruk
+
+import (
+	"bytes"
+	"gotest.tools/assert"
+	"testing"
+)
+
+var testReport = Report{
+	Name:     "test_sla",
+	Template: "sla_host.tt",
+	Params:   ReportParams{"host": "localhost", "timeperiod": "last24hours", "sla": 99},
+}
+
+func Test_thruk_client_Reports(t *testing.T) {
+	t.Run("Create, get and delete a report", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		nr, err := thruk.CreateReport(testReport)
+		assert.NilError(t, err)
+
+		report, err := thruk.GetReport(nr)
+		assert.NilError(t, err)
+		assert.Equal(t, report.Name, "test_sla")
+		assert.Equal(t, report.Template, "sla_host.tt")
+
+		assert.NilError(t, thruk.DeleteReport(nr))
+		_, err = thruk.GetReport(nr)
+		assert.Assert(t, err != nil)
+	})
+	t.Run("Update a report", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+		nr, err := thruk.CreateReport(testReport)
+		assert.NilError(t, err)
+
+		report := testReport
+		report.NR = nr
+		report.Description = "updated"
+		assert.NilError(t, thruk.UpdateReport(report))
+
+		report, err = thruk.GetReport(nr)
+		assert.NilError(t, err)
+		assert.Equal(t, report.Description, "updated")
+	})
+	t.Run("List reports contains created report", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+		nr, err := thruk.CreateReport(testReport)
+		assert.NilError(t, err)
+
+		reports, err := thruk.ListReports()
+		assert.NilError(t, err)
+		found := false
+		for _, report := range reports {
+			found = found || report.NR == nr
+		}
+		assert.Assert(t, found)
+	})
+	t.Run("Generate and download a report", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+		nr, err := thruk.CreateReport(testReport)
+		assert.NilError(t, err)
+
+		assert.NilError(t, thruk.GenerateReport(nr))
+		var buf bytes.Buffer
+		_, err = thruk.DownloadReport(nr, &buf)
+		assert.NilError(t, err)
+	})
+	t.Run("Report without name or template is rejected", func(t *testing.T) {
+		thruk := NewThruk("http://localhost", "site", "user", "pass", false)
+
+		_, err := thruk.CreateReport(Report{Name: "x"})
+		assert.Equal(t, err, ErrorInvalidInput)
+		assert.Equal(t, thruk.UpdateReport(Report{Name: "x"}), ErrorNeedID)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"net/url"
 )
 
 type Service struct {
@@ -64,12 +65,10 @@ func (t Thruk) GetService(id string) (Service, error) {
 	if id == "" {
 		return Service{}, ErrorInvalidInput
 	}
-	resp, err := t.GetURL("/" + t.SiteName + "/thruk/r/config/objects?:TYPE=service&:ID=" + id)
-	failOnError(err)
-	defer resp.Body.Close()
-
-	err = json.NewDecoder(resp.Body).Decode(&services)
-	failOnError(err)
+	err := t.findConfigObjects(url.Values{":TYPE": {"service"}, ":ID": {id}}, &services)
+	if err != nil {
+		return Service{}, err
+	}
 	if len(services) == 0 {
 		return Service{}, ErrorObjectNotFound
 	}
@@ -104,6 +103,35 @@ func (t Thruk) CreateService(service Service) (string, error) {
 	return thrukResp.Objects[0].ID, err
 }
 
+// GetServiceByName looks a service up by its natural key, the host it is
+// bound to and its service_description.
+func (t Thruk) GetServiceByName(hostName, description string) (Service, error) {
+	var services []Service
+	if hostName == "" || description == "" {
+		return Service{}, ErrorInvalidInput
+	}
+	filter := url.Values{":TYPE": {"service"}, "host_name": {hostName}, "service_description": {description}}
+	err := t.findConfigObjects(filter, &services)
+	if err != nil {
+		return Service{}, err
+	}
+	if len(services) == 0 {
+		return Service{}, ErrorObjectNotFound
+	}
+	if len(services) > 1 {
+		return Service{}, ErrorAmbiguousName
+	}
+
+	return services[0], nil
+}
+
+func (t Thruk) UpdateService(service Service) error {
+	if service.ID == "" {
+		return ErrorNeedID
+	}
+	return t.replaceConfigObject(service.ID, service)
+}
+
 func (t Thruk) DeleteService(id string) error {
 	URL := "/" + t.SiteName + "/thruk/r/config/objects/" + id
 	err := t.DeleteURL(URL)
@@ -94,4 +94,40 @@ func Test_thruk_client_ConfigObject_Service(t *testing.T) {
 		_, err := thruk.GetService(id)
 		assert.Error(t, err, "[ERROR] Object not found")
 	})
+	t.Run("Get service by host name and description returns service", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateService(Service{
+			FILE:               "test.cfg",
+			TYPE:               "service",
+			HostName:           []string{"localhost"},
+			ServiceDescription: "ping",
+		})
+		assert.NilError(t, err)
+
+		object, err := thruk.GetServiceByName("localhost", "ping")
+		assert.NilError(t, err)
+		assert.Equal(t, object.ID, id)
+	})
+	t.Run("Update service replaces its attributes", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateService(Service{
+			FILE:               "test.cfg",
+			TYPE:               "service",
+			HostName:           []string{"localhost"},
+			ServiceDescription: "ping",
+		})
+		assert.NilError(t, err)
+		service, err := thruk.GetService(id)
+		assert.NilError(t, err)
+
+		service.CheckInterval = "5"
+		err = thruk.UpdateService(service)
+		assert.NilError(t, err)
+
+		updated, err := thruk.GetService(id)
+		assert.NilError(t, err)
+		assert.Equal(t, updated.CheckInterval, "5")
+	})
 }
@@ -4,24 +4,68 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"net/url"
+	"sort"
+	"strings"
 )
 
+var ErrorGroupCycle = errors.New("[ERROR] group membership contains a cycle")
+
+// ServiceRef identifies a service by its host and service description.
+type ServiceRef struct {
+	Host    string
+	Service string
+}
+
+// ServiceRefs is encoded like servicegroup members in the object
+// configuration: a flat list of alternating host and service names.
+type ServiceRefs []ServiceRef
+
+func (refs ServiceRefs) MarshalJSON() ([]byte, error) {
+	flat := make([]string, 0, 2*len(refs))
+	for _, ref := range refs {
+		flat = append(flat, ref.Host, ref.Service)
+	}
+	return json.Marshal(flat)
+}
+
+func (refs *ServiceRefs) UnmarshalJSON(data []byte) error {
+	var flat []string
+	if err := json.Unmarshal(data, &flat); err != nil {
+		var joined string
+		if json.Unmarshal(data, &joined) != nil {
+			return err
+		}
+		for _, value := range strings.Split(joined, ",") {
+			flat = append(flat, strings.TrimSpace(value))
+		}
+	}
+	if len(flat)%2 != 0 {
+		return errors.New("servicegroup members must be host,service pairs")
+	}
+	*refs = nil
+	for i := 0; i < len(flat); i += 2 {
+		*refs = append(*refs, ServiceRef{Host: flat[i], Service: flat[i+1]})
+	}
+	return nil
+}
+
 type Servicegroup struct {
-	FILE                string   `json:":FILE"`
-	ID                  string   `json:":ID,omitempty"`
-	PEERKEY             string   `json:":PEER_KEY,omitempty"`
-	READONLY            int      `json:":READONLY,omitempty"`
-	TYPE                string   `json:":TYPE"`
-	ActionURL           string   `json:"action_url,omitempty"`
-	Alias               string   `json:"alias,omitempty"`
-	Members             []string `json:"members,omitempty"`
-	Name                string   `json:"name"`
-	Notes               string   `json:"notes,omitempty"`
-	NotesURL            string   `json:"notes_url,omitempty"`
-	Register            string   `json:"register,omitempty"`
-	ServicegroupMembers []string `json:"servicegroup_members,omitempty"`
-	ServicegroupName    string   `json:"servicegroup_name,omitempty"`
-	Use                 []string `json:"use,omitempty,omitempty"`
+	FILE                string      `json:":FILE"`
+	ID                  string      `json:":ID,omitempty"`
+	PEERKEY             string      `json:":PEER_KEY,omitempty"`
+	READONLY            int         `json:":READONLY,omitempty"`
+	TYPE                string      `json:":TYPE"`
+	ActionURL           string      `json:"action_url,omitempty"`
+	Alias               string      `json:"alias,omitempty"`
+	Members             ServiceRefs `json:"members,omitempty"`
+	Name                string      `json:"name"`
+	Notes               string      `json:"notes,omitempty"`
+	NotesURL            string      `json:"notes_url,omitempty"`
+	Register            string      `json:"register,omitempty"`
+	ServicegroupMembers []string    `json:"servicegroup_members,omitempty"`
+	ServicegroupName    string      `json:"servicegroup_name,omitempty"`
+	Use                 []string    `json:"use,omitempty,omitempty"`
 }
 
 func (t Thruk) GetServicegroup(id string) (Servicegroup, error) {
@@ -29,12 +73,10 @@ func (t Thruk) GetServicegroup(id string) (Servicegroup, error) {
 	if id == "" {
 		return Servicegroup{}, ErrorInvalidInput
 	}
-	resp, err := t.GetURL("/" + t.SiteName + "/thruk/r/config/objects?:TYPE=servicegroup&:ID=" + id)
-	failOnError(err)
-	defer resp.Body.Close()
-
-	err = json.NewDecoder(resp.Body).Decode(&servicegroups)
-	failOnError(err)
+	err := t.findConfigObjects(url.Values{":TYPE": {"servicegroup"}, ":ID": {id}}, &servicegroups)
+	if err != nil {
+		return Servicegroup{}, err
+	}
 	if len(servicegroups) == 0 {
 		return Servicegroup{}, ErrorObjectNotFound
 	}
@@ -69,6 +111,32 @@ func (t Thruk) CreateServicegroup(servicegroup Servicegroup) (string, error) {
 	return thrukResp.Objects[0].ID, err
 }
 
+func (t Thruk) GetServicegroupByName(name string) (Servicegroup, error) {
+	var servicegroups []Servicegroup
+	if name == "" {
+		return Servicegroup{}, ErrorInvalidInput
+	}
+	err := t.findConfigObjects(url.Values{":TYPE": {"servicegroup"}, "servicegroup_name": {name}}, &servicegroups)
+	if err != nil {
+		return Servicegroup{}, err
+	}
+	if len(servicegroups) == 0 {
+		return Servicegroup{}, ErrorObjectNotFound
+	}
+	if len(servicegroups) > 1 {
+		return Servicegroup{}, ErrorAmbiguousName
+	}
+
+	return servicegroups[0], nil
+}
+
+func (t Thruk) UpdateServicegroup(servicegroup Servicegroup) error {
+	if servicegroup.ID == "" {
+		return ErrorNeedID
+	}
+	return t.replaceConfigObject(servicegroup.ID, servicegroup)
+}
+
 func (t Thruk) DeleteServicegroup(id string) error {
 	URL := "/" + t.SiteName + "/thruk/r/config/objects/" + id
 	err := t.DeleteURL(URL)
@@ -78,3 +146,159 @@ func (t Thruk) DeleteServicegroup(id string) error {
 
 	return nil
 }
+
+// SetServicegroupMembers replaces the members of a servicegroup, see
+// SetGroupMembers.
+func (t Thruk) SetServicegroupMembers(id string, members []ServiceRef) error {
+	flat := make([]string, 0, 2*len(members))
+	for _, ref := range members {
+		flat = append(flat, ref.Host, ref.Service)
+	}
+	return t.SetGroupMembers(id, flat)
+}
+
+// EffectiveMembers returns every service that belongs to a servicegroup:
+// its members, the members of nested servicegroups and all services that
+// list the group in their servicegroups, expanded over their hosts and
+// hostgroups. Inheritance through templates is not resolved.
+func (t Thruk) EffectiveMembers(servicegroupID string) ([]ServiceRef, error) {
+	group, err := t.getRawObject(servicegroupID, "servicegroup")
+	if err != nil {
+		return nil, err
+	}
+	objects, err := t.listRawConfigObjects(nil)
+	if err != nil {
+		return nil, err
+	}
+	m := membership{byType: map[string]map[string]rawObject{}}
+	for _, object := range objects {
+		if object.str("register") == "0" {
+			continue
+		}
+		objectType := object.objectType()
+		if m.byType[objectType] == nil {
+			m.byType[objectType] = map[string]rawObject{}
+		}
+		if name := object.str(nameAttributes[objectType]); name != "" {
+			m.byType[objectType][name] = object
+		}
+		if objectType == "service" {
+			m.services = append(m.services, object)
+		}
+		if objectType == "host" {
+			m.hosts = append(m.hosts, object)
+		}
+	}
+
+	found := map[ServiceRef]bool{}
+	err = m.servicegroupMembers(group.str("servicegroup_name"), map[string]bool{}, found)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]ServiceRef, 0, len(found))
+	for ref := range found {
+		members = append(members, ref)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Host != members[j].Host {
+			return members[i].Host < members[j].Host
+		}
+		return members[i].Service < members[j].Service
+	})
+	return members, nil
+}
+
+type membership struct {
+	byType   map[string]map[string]rawObject
+	hosts    []rawObject
+	services []rawObject
+}
+
+func (m membership) servicegroupMembers(name string, visiting map[string]bool, found map[ServiceRef]bool) error {
+	if visiting[name] {
+		return ErrorGroupCycle
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	group := m.byType["servicegroup"][name]
+	members := group.list("members")
+	for i := 0; i+1 < len(members); i += 2 {
+		found[ServiceRef{Host: members[i], Service: members[i+1]}] = true
+	}
+	for _, service := range m.services {
+		if !contains(service.list("servicegroups"), name) {
+			continue
+		}
+		hosts, err := m.serviceHosts(service)
+		if err != nil {
+			return err
+		}
+		for _, host := range hosts {
+			found[ServiceRef{Host: host, Service: service.str("service_description")}] = true
+		}
+	}
+	for _, nested := range group.list("servicegroup_members") {
+		if err := m.servicegroupMembers(nested, visiting, found); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serviceHosts expands host_name and hostgroup_name of a service, honoring
+// hosts excluded with a leading "!".
+func (m membership) serviceHosts(service rawObject) ([]string, error) {
+	hosts := map[string]bool{}
+	excluded := map[string]bool{}
+	for _, host := range service.list("host_name") {
+		if strings.HasPrefix(host, "!") {
+			excluded[host[1:]] = true
+		} else {
+			hosts[host] = true
+		}
+	}
+	for _, hostgroup := range service.list("hostgroup_name") {
+		if strings.HasPrefix(hostgroup, "!") {
+			err := m.hostgroupMembers(hostgroup[1:], map[string]bool{}, excluded)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := m.hostgroupMembers(hostgroup, map[string]bool{}, hosts); err != nil {
+			return nil, err
+		}
+	}
+	var names []string
+	for host := range hosts {
+		if !excluded[host] {
+			names = append(names, host)
+		}
+	}
+	return names, nil
+}
+
+func (m membership) hostgroupMembers(name string, visiting map[string]bool, found map[string]bool) error {
+	if visiting[name] {
+		return ErrorGroupCycle
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	group := m.byType["hostgroup"][name]
+	for _, host := range group.list("members") {
+		found[host] = true
+	}
+	for _, host := range m.hosts {
+		if contains(host.list("hostgroups"), name) {
+			found[host.str("host_name")] = true
+		}
+	}
+	for _, nested := range group.list("hostgroup_members") {
+		if err := m.hostgroupMembers(nested, visiting, found); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package thruk
 
 import (
+	"encoding/json"
 	"gotest.tools/assert"
 	"testing"
 )
@@ -105,4 +106,92 @@ func Test_thruk_client_Servicegroup(t *testing.T) {
 		_, err := thruk.GetServicegroup(id)
 		assert.Error(t, err, "[ERROR] Object not found")
 	})
+	t.Run("Get servicegroup by name returns servicegroup", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateServicegroup(Servicegroup{
+			FILE:             "test.cfg",
+			TYPE:             "servicegroup",
+			ServicegroupName: "by_name",
+		})
+		assert.NilError(t, err)
+
+		object, err := thruk.GetServicegroupByName("by_name")
+		assert.NilError(t, err)
+		assert.Equal(t, object.ID, id)
+	})
+	t.Run("Update servicegroup replaces its attributes", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateServicegroup(Servicegroup{
+			FILE:             "test.cfg",
+			TYPE:             "servicegroup",
+			ServicegroupName: "update",
+		})
+		assert.NilError(t, err)
+		servicegroup, err := thruk.GetServicegroup(id)
+		assert.NilError(t, err)
+
+		servicegroup.Alias = "updated"
+		err = thruk.UpdateServicegroup(servicegroup)
+		assert.NilError(t, err)
+
+		updated, err := thruk.GetServicegroup(id)
+		assert.NilError(t, err)
+		assert.Equal(t, updated.Alias, "updated")
+	})
+}
+
+func Test_ServiceRefs_json(t *testing.T) {
+	t.Run("ServiceRefs encode as alternating host and service names", func(t *testing.T) {
+		encoded, err := json.Marshal(ServiceRefs{{Host: "web-07", Service: "http"}, {Host: "db-01", Service: "ping"}})
+		assert.NilError(t, err)
+		assert.Equal(t, string(encoded), `["web-07","http","db-01","ping"]`)
+	})
+	t.Run("ServiceRefs decode from a list or a comma separated string", func(t *testing.T) {
+		var fromList, fromString ServiceRefs
+		assert.NilError(t, json.Unmarshal([]byte(`["web-07","http"]`), &fromList))
+		assert.NilError(t, json.Unmarshal([]byte(`"web-07, http"`), &fromString))
+		assert.DeepEqual(t, fromList, ServiceRefs{{Host: "web-07", Service: "http"}})
+		assert.DeepEqual(t, fromString, ServiceRefs{{Host: "web-07", Service: "http"}})
+	})
+	t.Run("ServiceRefs with an odd number of names return error", func(t *testing.T) {
+		var refs ServiceRefs
+		err := json.Unmarshal([]byte(`["web-07"]`), &refs)
+		assert.Error(t, err, "servicegroup members must be host,service pairs")
+	})
+}
+
+func Test_thruk_client_EffectiveMembers(t *testing.T) {
+	t.Run("Effective members expand nested groups, services and hostgroups", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.CreateHostgroup(Hostgroup{FILE: "effective.cfg", TYPE: "hostgroup", HostgroupName: "web-servers", Members: []string{"web-01", "web-02"}})
+		assert.NilError(t, err)
+		_, err = thruk.CreateService(Service{FILE: "effective.cfg", TYPE: "service", HostgroupName: []string{"web-servers"}, HostName: []string{"!web-02"}, ServiceDescription: "http", Servicegroups: []string{"effective"}})
+		assert.NilError(t, err)
+		_, err = thruk.CreateServicegroup(Servicegroup{FILE: "effective.cfg", TYPE: "servicegroup", ServicegroupName: "nested", Members: ServiceRefs{{Host: "db-01", Service: "ping"}}})
+		assert.NilError(t, err)
+		id, err := thruk.CreateServicegroup(Servicegroup{FILE: "effective.cfg", TYPE: "servicegroup", ServicegroupName: "effective", ServicegroupMembers: []string{"nested"}, Members: ServiceRefs{{Host: "web-02", Service: "load"}}})
+		assert.NilError(t, err)
+
+		members, err := thruk.EffectiveMembers(id)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, members, []ServiceRef{
+			{Host: "db-01", Service: "ping"},
+			{Host: "web-01", Service: "http"},
+			{Host: "web-02", Service: "load"},
+		})
+	})
+	t.Run("Effective members of nested groups with a cycle return error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.CreateServicegroup(Servicegroup{FILE: "effective.cfg", TYPE: "servicegroup", ServicegroupName: "cycle-b", ServicegroupMembers: []string{"cycle-a"}})
+		assert.NilError(t, err)
+		id, err := thruk.CreateServicegroup(Servicegroup{FILE: "effective.cfg", TYPE: "servicegroup", ServicegroupName: "cycle-a", ServicegroupMembers: []string{"cycle-b"}})
+		assert.NilError(t, err)
+
+		_, err = thruk.EffectiveMembers(id)
+		assert.Error(t, err, "[ERROR] group membership contains a cycle")
+	})
 }
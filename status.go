@@ -0,0 +1,94 @@
+package thruk
+
+import (
+	"context"
+	"net/url"
+)
+
+type HostStatus struct {
+	Name                   string   `json:"name"`
+	DisplayName            string   `json:"display_name,omitempty"`
+	Address                string   `json:"address,omitempty"`
+	State                  int      `json:"state"`
+	StateType              int      `json:"state_type"`
+	HasBeenChecked         int      `json:"has_been_checked"`
+	CurrentAttempt         int      `json:"current_attempt"`
+	MaxCheckAttempts       int      `json:"max_check_attempts"`
+	Acknowledged           int      `json:"acknowledged"`
+	ScheduledDowntimeDepth int      `json:"scheduled_downtime_depth"`
+	IsFlapping             int      `json:"is_flapping"`
+	Latency                float64  `json:"latency"`
+	ExecutionTime          float64  `json:"execution_time"`
+	PluginOutput           string   `json:"plugin_output,omitempty"`
+	PerfData               string   `json:"perf_data,omitempty"`
+	LastCheck              int64    `json:"last_check"`
+	LastStateChange        int64    `json:"last_state_change"`
+	Groups                 []string `json:"groups,omitempty"`
+	PeerKey                string   `json:"peer_key,omitempty"`
+	PeerName               string   `json:"peer_name,omitempty"`
+}
+
+type ServiceStatus struct {
+	HostName               string   `json:"host_name"`
+	Description            string   `json:"description"`
+	DisplayName            string   `json:"display_name,omitempty"`
+	State                  int      `json:"state"`
+	StateType              int      `json:"state_type"`
+	HasBeenChecked         int      `json:"has_been_checked"`
+	CurrentAttempt         int      `json:"current_attempt"`
+	MaxCheckAttempts       int      `json:"max_check_attempts"`
+	Acknowledged           int      `json:"acknowledged"`
+	ScheduledDowntimeDepth int      `json:"scheduled_downtime_depth"`
+	IsFlapping             int      `json:"is_flapping"`
+	Latency                float64  `json:"latency"`
+	ExecutionTime          float64  `json:"execution_time"`
+	PluginOutput           string   `json:"plugin_output,omitempty"`
+	PerfData               string   `json:"perf_data,omitempty"`
+	LastCheck              int64    `json:"last_check"`
+	LastStateChange        int64    `json:"last_state_change"`
+	Groups                 []string `json:"groups,omitempty"`
+	HostGroups             []string `json:"host_groups,omitempty"`
+	PeerKey                string   `json:"peer_key,omitempty"`
+	PeerName               string   `json:"peer_name,omitempty"`
+}
+
+// Site is one of the monitoring backends (peers) Thruk is connected to.
+type Site struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Address    string  `json:"addr,omitempty"`
+	Type       string  `json:"type,omitempty"`
+	Connected  int     `json:"connected"`
+	Status     int     `json:"status"`
+	LastError  string  `json:"last_error,omitempty"`
+	LastOnline float64 `json:"last_online,omitempty"`
+}
+
+// ListHostStatus returns the current state of all hosts matching filter,
+// using Thruk's query parameter syntax (e.g. state=1, name[regex]=^web).
+func (t Thruk) ListHostStatus(filter url.Values) ([]HostStatus, error) {
+	var hosts []HostStatus
+	err := t.listAll(context.Background(), "hosts", filter, &hosts)
+	if err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
+func (t Thruk) ListServiceStatus(filter url.Values) ([]ServiceStatus, error) {
+	var services []ServiceStatus
+	err := t.listAll(context.Background(), "services", filter, &services)
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+func (t Thruk) ListSites() ([]Site, error) {
+	var sites []Site
+	err := t.listAll(context.Background(), "sites", nil, &sites)
+	if err != nil {
+		return nil, err
+	}
+	return sites, nil
+}
@@ -0,0 +1,47 @@
+package thruk
+
+import (
+	"gotest.tools/assert"
+	"net/url"
+	"testing"
+)
+
+func Test_thruk_client_Status(t *testing.T) {
+	t.Run("List sites returns the connected demo backend", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		sites, err := thruk.ListSites()
+		assert.NilError(t, err)
+		assert.Equal(t, len(sites), 1)
+		assert.Equal(t, sites[0].ID, "48f1c")
+		assert.Equal(t, sites[0].Connected, 1)
+	})
+	t.Run("List host status returns hosts", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.ListHostStatus(nil)
+		assert.NilError(t, err)
+	})
+	t.Run("List host status with unknown name returns no hosts", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		hosts, err := thruk.ListHostStatus(url.Values{"name": {"not_existent"}})
+		assert.NilError(t, err)
+		assert.Equal(t, len(hosts), 0)
+	})
+	t.Run("List service status returns services", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.ListServiceStatus(nil)
+		assert.NilError(t, err)
+	})
+}
+
+func Test_thruk_client_Downtimes(t *testing.T) {
+	t.Run("List downtimes returns no error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.ListDowntimes(nil)
+		assert.NilError(t, err)
+	})
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// objectDataSource looks up an object by its :ID or its key.
+type objectDataSource struct {
+	objectType
+	provider *providerData
+}
+
+var _ datasource.DataSourceWithConfigure = &objectDataSource{}
+
+func (d *objectDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + d.name
+}
+
+func (d *objectDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := map[string]schema.Attribute{
+		"id": schema.StringAttribute{Optional: true, Computed: true, MarkdownDescription: "The `:ID` of the object."},
+	}
+	for _, a := range d.attributes() {
+		lookup := d.isKey(a.name)
+		description := fmt.Sprintf("The `%s` attribute.", a.thrukKey)
+		switch a.kind {
+		case kindString:
+			attributes[a.name] = schema.StringAttribute{Optional: lookup, Computed: true, MarkdownDescription: description}
+		case kindList:
+			attributes[a.name] = schema.ListAttribute{Optional: lookup, Computed: true, ElementType: types.StringType, MarkdownDescription: description}
+		case kindServiceRefs:
+			attributes[a.name] = schema.ListAttribute{Computed: true, ElementType: serviceRefType, MarkdownDescription: "The services in the group."}
+		case kindExceptions:
+			attributes[a.name] = schema.MapAttribute{Computed: true, ElementType: types.StringType, MarkdownDescription: "Exceptions keyed by their date."}
+		}
+	}
+	resp.Schema = schema.Schema{
+		MarkdownDescription: fmt.Sprintf("Looks up a Thruk %s by its `id` or by %v.", d.name, d.key),
+		Attributes:          attributes,
+	}
+}
+
+func (d *objectDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData != nil {
+		d.provider = req.ProviderData.(*providerData)
+	}
+}
+
+func (d *objectDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var id types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("id"), &id)...)
+	config, diags := d.values(ctx, req.Config.GetAttribute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var object interface{}
+	var err error
+	if id.ValueString() != "" {
+		object, err = d.get(d.provider.thruk, id.ValueString())
+	} else {
+		var key []string
+		for _, name := range d.key {
+			value := ""
+			switch v := config[name].(type) {
+			case types.String:
+				value = v.ValueString()
+			case types.List:
+				// the hosts of a service, only one can be looked up
+				if len(v.Elements()) == 1 {
+					value = v.Elements()[0].(types.String).ValueString()
+				}
+			}
+			if value == "" {
+				resp.Diagnostics.AddError("Looking up "+d.name, fmt.Sprintf("set id or a single value for each of %v", d.key))
+				return
+			}
+			key = append(key, value)
+		}
+		object, err = d.getByKey(d.provider.thruk, key)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Looking up "+d.name, err.Error())
+		return
+	}
+
+	values, diags := d.fromObject(ctx, object, nil)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), objectID(object))...)
+	for name, value := range values {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(name), value)...)
+	}
+}
@@ -0,0 +1,53 @@
+// Command terraform-provider-thruk is a Terraform provider for the object
+// configuration of Thruk, built on the thruk-go client:
+//
+//	provider "thruk" {
+//	  url  = "https://monitoring.example.com"
+//	  site = "prod"
+//	}
+//
+//	resource "thruk_command" "ping" {
+//	  file         = "conf.d/commands.cfg"
+//	  command_name = "check_ping"
+//	  command_line = "$USER1$/check_ping -H $HOSTADDRESS$ -w 100,20% -c 500,60%"
+//	}
+//
+//	resource "thruk_host" "web01" {
+//	  file          = "conf.d/hosts.cfg"
+//	  host_name     = "web01"
+//	  address       = "10.0.0.7"
+//	  use           = ["generic-host"]
+//	  check_command = thruk_command.ping.command_name
+//	}
+//
+// There are resources and data sources for hosts, services, commands,
+// hostgroups, servicegroups, contacts and timeperiods. Objects are
+// imported by their :ID or their name, host/description for services:
+//
+//	terraform import thruk_service.http web01/http
+//
+// Every change is saved, checked and reloaded, see save_and_reload.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+)
+
+var version = "dev"
+
+func main() {
+	debug := flag.Bool("debug", false, "run with support for debuggers like delve")
+	flag.Parse()
+
+	err := providerserver.Serve(context.Background(), New(version), providerserver.ServeOpts{
+		Address: "registry.terraform.io/roviluca/thruk",
+		Debug:   *debug,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	thruk "gitlab.com/roviluca/thruk-go"
+)
+
+// objectType maps a Thruk object type to the typed calls of the client.
+// Its resource and data source attributes are derived from the client
+// struct, so they follow the attributes the client knows.
+type objectType struct {
+	name string
+	// key holds the attributes identifying an object besides its :ID, used
+	// by data sources and imports.
+	key      []string
+	object   interface{}
+	get      func(c *thruk.Thruk, id string) (interface{}, error)
+	getByKey func(c *thruk.Thruk, key []string) (interface{}, error)
+	create   func(c *thruk.Thruk, object interface{}) (string, error)
+	delete   func(c *thruk.Thruk, id string) error
+}
+
+var objectTypes = []objectType{
+	{
+		name: "host", key: []string{"host_name"}, object: thruk.Host{},
+		get:      func(c *thruk.Thruk, id string) (interface{}, error) { return c.GetHost(id) },
+		getByKey: func(c *thruk.Thruk, key []string) (interface{}, error) { return c.GetHostByName(key[0]) },
+		create:   func(c *thruk.Thruk, o interface{}) (string, error) { return c.CreateHost(o.(thruk.Host)) },
+		delete:   func(c *thruk.Thruk, id string) error { return c.DeleteHost(id) },
+	},
+	{
+		name: "service", key: []string{"host_name", "service_description"}, object: thruk.Service{},
+		get: func(c *thruk.Thruk, id string) (interface{}, error) { return c.GetService(id) },
+		getByKey: func(c *thruk.Thruk, key []string) (interface{}, error) {
+			return c.GetServiceByName(key[0], key[1])
+		},
+		create: func(c *thruk.Thruk, o interface{}) (string, error) { return c.CreateService(o.(thruk.Service)) },
+		delete: func(c *thruk.Thruk, id string) error { return c.DeleteService(id) },
+	},
+	{
+		name: "command", key: []string{"command_name"}, object: thruk.Command{},
+		get:      func(c *thruk.Thruk, id string) (interface{}, error) { return c.GetCommand(id) },
+		getByKey: func(c *thruk.Thruk, key []string) (interface{}, error) { return c.GetCommandByName(key[0]) },
+		create:   func(c *thruk.Thruk, o interface{}) (string, error) { return c.CreateCommand(o.(thruk.Command)) },
+		delete:   func(c *thruk.Thruk, id string) error { return c.DeleteCommand(id) },
+	},
+	{
+		name: "hostgroup", key: []string{"hostgroup_name"}, object: thruk.Hostgroup{},
+		get:      func(c *thruk.Thruk, id string) (interface{}, error) { return c.GetHostgroup(id) },
+		getByKey: func(c *thruk.Thruk, key []string) (interface{}, error) { return c.GetHostgroupByName(key[0]) },
+		create:   func(c *thruk.Thruk, o interface{}) (string, error) { return c.CreateHostgroup(o.(thruk.Hostgroup)) },
+		delete:   func(c *thruk.Thruk, id string) error { return c.DeleteHostgroup(id) },
+	},
+	{
+		name: "servicegroup", key: []string{"servicegroup_name"}, object: thruk.Servicegroup{},
+		get: func(c *thruk.Thruk, id string) (interface{}, error) { return c.GetServicegroup(id) },
+		getByKey: func(c *thruk.Thruk, key []string) (interface{}, error) {
+			return c.GetServicegroupByName(key[0])
+		},
+		create: func(c *thruk.Thruk, o interface{}) (string, error) {
+			return c.CreateServicegroup(o.(thruk.Servicegroup))
+		},
+		delete: func(c *thruk.Thruk, id string) error { return c.DeleteServicegroup(id) },
+	},
+	{
+		name: "contact", key: []string{"contact_name"}, object: thruk.Contact{},
+		get:      func(c *thruk.Thruk, id string) (interface{}, error) { return c.GetContact(id) },
+		getByKey: func(c *thruk.Thruk, key []string) (interface{}, error) { return c.GetContactByName(key[0]) },
+		create:   func(c *thruk.Thruk, o interface{}) (string, error) { return c.CreateContact(o.(thruk.Contact)) },
+		delete:   func(c *thruk.Thruk, id string) error { return c.DeleteContact(id) },
+	},
+	{
+		name: "timeperiod", key: []string{"timeperiod_name"}, object: thruk.Timeperiod{},
+		get: func(c *thruk.Thruk, id string) (interface{}, error) { return c.GetTimeperiod(id) },
+		getByKey: func(c *thruk.Thruk, key []string) (interface{}, error) {
+			return c.GetTimeperiodByName(key[0])
+		},
+		create: func(c *thruk.Thruk, o interface{}) (string, error) {
+			return c.CreateTimeperiod(o.(thruk.Timeperiod))
+		},
+		delete: func(c *thruk.Thruk, id string) error { return c.DeleteTimeperiod(id) },
+	},
+}
+
+type attributeKind int
+
+const (
+	kindString attributeKind = iota
+	kindList
+	// kindServiceRefs are servicegroup members, a list of host and service
+	// objects.
+	kindServiceRefs
+	// kindExceptions are timeperiod exceptions, a map of the definition to
+	// its time ranges. Each is a top level attribute in Thruk.
+	kindExceptions
+)
+
+// attribute is a Terraform attribute and the client struct field and Thruk
+// attribute it maps to.
+type attribute struct {
+	name     string
+	thrukKey string
+	field    int
+	kind     attributeKind
+}
+
+// attributeNames renames Thruk attributes that are not valid Terraform
+// attribute names.
+var attributeNames = map[string]string{
+	"2d_coords": "coords_2d",
+	"3d_coords": "coords_3d",
+	"_WORKER":   "worker",
+}
+
+var serviceRefType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"host":    types.StringType,
+	"service": types.StringType,
+}}
+
+type serviceRef struct {
+	Host    string `tfsdk:"host"`
+	Service string `tfsdk:"service"`
+}
+
+func (o objectType) attributes() []attribute {
+	var attributes []attribute
+	fields := reflect.TypeOf(o.object)
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		thrukKey := strings.Split(field.Tag.Get("json"), ",")[0]
+		a := attribute{thrukKey: thrukKey, field: i}
+		switch {
+		case field.Type == reflect.TypeOf(thruk.ServiceRefs{}):
+			a.kind = kindServiceRefs
+		case field.Type == reflect.TypeOf(map[string]string{}):
+			a.kind, a.thrukKey = kindExceptions, ""
+		case field.Type.Kind() == reflect.Slice:
+			a.kind = kindList
+		case field.Type.Kind() != reflect.String:
+			continue
+		}
+		switch {
+		case a.kind == kindExceptions:
+			a.name = strings.ToLower(field.Name)
+		case thrukKey == ":FILE":
+			a.name = "file"
+		case strings.HasPrefix(thrukKey, ":"):
+			// :ID is the id attribute, :TYPE is given by the resource and
+			// :PEER_KEY is set by Thruk
+			continue
+		case attributeNames[thrukKey] != "":
+			a.name = attributeNames[thrukKey]
+		default:
+			a.name = thrukKey
+		}
+		attributes = append(attributes, a)
+	}
+	return attributes
+}
+
+func (o objectType) isKey(name string) bool {
+	for _, key := range o.key {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+type getter func(context.Context, path.Path, interface{}) diag.Diagnostics
+
+// values reads the attributes from a plan, state or config.
+func (o objectType) values(ctx context.Context, get getter) (map[string]attr.Value, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	values := map[string]attr.Value{}
+	for _, a := range o.attributes() {
+		var value attr.Value
+		switch a.kind {
+		case kindString:
+			var v types.String
+			diags.Append(get(ctx, path.Root(a.name), &v)...)
+			value = v
+		case kindList, kindServiceRefs:
+			var v types.List
+			diags.Append(get(ctx, path.Root(a.name), &v)...)
+			value = v
+		case kindExceptions:
+			var v types.Map
+			diags.Append(get(ctx, path.Root(a.name), &v)...)
+			value = v
+		}
+		values[a.name] = value
+	}
+	return values, diags
+}
+
+// toObject returns the client struct holding values.
+func (o objectType) toObject(ctx context.Context, values map[string]attr.Value) (interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	object := reflect.New(reflect.TypeOf(o.object)).Elem()
+	object.FieldByName("TYPE").SetString(o.name)
+	for _, a := range o.attributes() {
+		value := values[a.name]
+		if value == nil || value.IsNull() || value.IsUnknown() {
+			continue
+		}
+		field := object.Field(a.field)
+		switch a.kind {
+		case kindString:
+			field.SetString(value.(types.String).ValueString())
+		case kindList:
+			var list []string
+			diags.Append(value.(types.List).ElementsAs(ctx, &list, false)...)
+			field.Set(reflect.ValueOf(list))
+		case kindServiceRefs:
+			var refs []serviceRef
+			diags.Append(value.(types.List).ElementsAs(ctx, &refs, false)...)
+			members := thruk.ServiceRefs{}
+			for _, ref := range refs {
+				members = append(members, thruk.ServiceRef{Host: ref.Host, Service: ref.Service})
+			}
+			field.Set(reflect.ValueOf(members))
+		case kindExceptions:
+			exceptions := map[string]string{}
+			diags.Append(value.(types.Map).ElementsAs(ctx, &exceptions, false)...)
+			field.Set(reflect.ValueOf(exceptions))
+		}
+	}
+	return object.Interface(), diags
+}
+
+// fromObject returns the values of a client struct. Thruk does not tell
+// empty from missing attributes, so an empty value is null unless prior
+// holds it as empty, which keeps configurations with empty values from
+// showing a diff on every plan.
+func (o objectType) fromObject(ctx context.Context, object interface{}, prior map[string]attr.Value) (map[string]attr.Value, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	values := map[string]attr.Value{}
+	fields := reflect.ValueOf(object)
+	for _, a := range o.attributes() {
+		field := fields.Field(a.field)
+		if field.Len() == 0 {
+			values[a.name] = nullValue(a.kind)
+			if before := prior[a.name]; before != nil && !before.IsNull() && !before.IsUnknown() && isEmpty(before) {
+				values[a.name] = before
+			}
+			continue
+		}
+		var value attr.Value
+		var d diag.Diagnostics
+		switch a.kind {
+		case kindString:
+			value = types.StringValue(field.String())
+		case kindList:
+			value, d = types.ListValueFrom(ctx, types.StringType, field.Interface())
+		case kindServiceRefs:
+			var refs []serviceRef
+			for _, member := range field.Interface().(thruk.ServiceRefs) {
+				refs = append(refs, serviceRef{Host: member.Host, Service: member.Service})
+			}
+			value, d = types.ListValueFrom(ctx, serviceRefType, refs)
+		case kindExceptions:
+			value, d = types.MapValueFrom(ctx, types.StringType, field.Interface())
+		}
+		diags.Append(d...)
+		values[a.name] = value
+	}
+	return values, diags
+}
+
+// patch returns the Thruk attributes changed from state to plan. Removed
+// attributes are nil, which deletes them, and attributes Terraform does not
+// manage, like custom variables, are left alone.
+func (o objectType) patch(ctx context.Context, plan, state map[string]attr.Value) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	changes := map[string]interface{}{}
+	for _, a := range o.attributes() {
+		if a.name == "file" || plan[a.name].Equal(state[a.name]) {
+			continue
+		}
+		value := plan[a.name]
+		empty := value.IsNull() || isEmpty(value)
+		switch a.kind {
+		case kindString:
+			changes[a.thrukKey] = nil
+			if !empty {
+				changes[a.thrukKey] = value.(types.String).ValueString()
+			}
+		case kindList:
+			changes[a.thrukKey] = nil
+			if !empty {
+				var list []string
+				diags.Append(value.(types.List).ElementsAs(ctx, &list, false)...)
+				changes[a.thrukKey] = list
+			}
+		case kindServiceRefs:
+			changes[a.thrukKey] = nil
+			if !empty {
+				var refs []serviceRef
+				diags.Append(value.(types.List).ElementsAs(ctx, &refs, false)...)
+				var flat []string
+				for _, ref := range refs {
+					flat = append(flat, ref.Host, ref.Service)
+				}
+				changes[a.thrukKey] = flat
+			}
+		case kindExceptions:
+			before, after := map[string]string{}, map[string]string{}
+			if !state[a.name].IsNull() {
+				diags.Append(state[a.name].(types.Map).ElementsAs(ctx, &before, false)...)
+			}
+			if !value.IsNull() {
+				diags.Append(value.(types.Map).ElementsAs(ctx, &after, false)...)
+			}
+			for key := range before {
+				if _, ok := after[key]; !ok {
+					changes[key] = nil
+				}
+			}
+			for key, ranges := range after {
+				if before[key] != ranges {
+					changes[key] = ranges
+				}
+			}
+		}
+	}
+	return changes, diags
+}
+
+func nullValue(kind attributeKind) attr.Value {
+	switch kind {
+	case kindList:
+		return types.ListNull(types.StringType)
+	case kindServiceRefs:
+		return types.ListNull(serviceRefType)
+	case kindExceptions:
+		return types.MapNull(types.StringType)
+	}
+	return types.StringNull()
+}
+
+func isEmpty(value attr.Value) bool {
+	switch v := value.(type) {
+	case types.String:
+		return v.ValueString() == ""
+	case types.List:
+		return len(v.Elements()) == 0
+	case types.Map:
+		return len(v.Elements()) == 0
+	}
+	return false
+}
+
+func objectID(object interface{}) string {
+	return reflect.ValueOf(object).FieldByName("ID").String()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	thruk "gitlab.com/roviluca/thruk-go"
+)
+
+type thrukProvider struct {
+	version string
+}
+
+func New(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &thrukProvider{version: version}
+	}
+}
+
+type providerModel struct {
+	URL           types.String `tfsdk:"url"`
+	Site          types.String `tfsdk:"site"`
+	Username      types.String `tfsdk:"username"`
+	Password      types.String `tfsdk:"password"`
+	SkipTLSVerify types.Bool   `tfsdk:"skip_tls_verify"`
+	SaveAndReload types.Bool   `tfsdk:"save_and_reload"`
+}
+
+func (p *thrukProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "thruk"
+	resp.Version = p.version
+}
+
+func (p *thrukProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"url":      schema.StringAttribute{Optional: true, MarkdownDescription: "The Thruk URL, or `THRUK_URL`."},
+			"site":     schema.StringAttribute{Optional: true, MarkdownDescription: "The OMD site, or `THRUK_SITE`."},
+			"username": schema.StringAttribute{Optional: true, MarkdownDescription: "The user, or `THRUK_USERNAME`."},
+			"password": schema.StringAttribute{Optional: true, Sensitive: true, MarkdownDescription: "The password, or `THRUK_PASSWORD`."},
+			"skip_tls_verify": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Skip verifying the certificate of Thruk.",
+			},
+			"save_and_reload": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Save, check and reload the configuration after every change, true when not set. " +
+					"Changes finishing while a reload runs are covered by the next one. When false, changes stay " +
+					"staged in Thruk until they are saved there.",
+			},
+		},
+	}
+}
+
+func (p *thrukProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config providerModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	setting := func(value types.String, env string) string {
+		if value.ValueString() != "" {
+			return value.ValueString()
+		}
+		return os.Getenv(env)
+	}
+	url := setting(config.URL, "THRUK_URL")
+	site := setting(config.Site, "THRUK_SITE")
+	if url == "" || site == "" {
+		resp.Diagnostics.AddError("Missing Thruk settings", "url and site must be set, or THRUK_URL and THRUK_SITE.")
+		return
+	}
+
+	data := &providerData{
+		thruk: thruk.NewThruk(url, site,
+			setting(config.Username, "THRUK_USERNAME"), setting(config.Password, "THRUK_PASSWORD"),
+			config.SkipTLSVerify.ValueBool()),
+		saveAndReload: config.SaveAndReload.IsNull() || config.SaveAndReload.ValueBool(),
+	}
+	resp.ResourceData = data
+	resp.DataSourceData = data
+}
+
+func (p *thrukProvider) Resources(context.Context) []func() resource.Resource {
+	var resources []func() resource.Resource
+	for _, o := range objectTypes {
+		o := o
+		resources = append(resources, func() resource.Resource { return &objectResource{objectType: o} })
+	}
+	return resources
+}
+
+func (p *thrukProvider) DataSources(context.Context) []func() datasource.DataSource {
+	var dataSources []func() datasource.DataSource
+	for _, o := range objectTypes {
+		o := o
+		dataSources = append(dataSources, func() datasource.DataSource { return &objectDataSource{objectType: o} })
+	}
+	return dataSources
+}
+
+// providerData is shared by the resources and data sources of a provider.
+type providerData struct {
+	thruk         *thruk.Thruk
+	saveAndReload bool
+
+	mu        sync.Mutex
+	changes   int64
+	committed int64
+}
+
+// commit saves and reloads the configuration after a change. Terraform
+// applies resources in parallel, so a change that finished while another
+// one was reloading is covered by a single following reload instead of
+// one per change.
+func (p *providerData) commit() error {
+	if !p.saveAndReload {
+		return nil
+	}
+	change := atomic.AddInt64(&p.changes, 1)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.committed >= change {
+		return nil
+	}
+	latest := atomic.LoadInt64(&p.changes)
+	if err := p.thruk.SaveAndReloadConfigs(); err != nil {
+		return err
+	}
+	p.committed = latest
+	return nil
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	thruk "gitlab.com/roviluca/thruk-go"
+	"gotest.tools/assert"
+)
+
+// fakeThruk keeps config objects in memory and records patches and saves.
+type fakeThruk struct {
+	sync.Mutex
+	objects map[string]map[string]interface{}
+	nextID  int
+	patches []map[string]interface{}
+	saves   int
+}
+
+func (f *fakeThruk) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	objectsPath := "/site/thruk/r/config/objects"
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, objectsPath), "/")
+	switch {
+	case r.Method == "GET" && r.URL.Path == objectsPath:
+		matching := []map[string]interface{}{}
+		for _, object := range f.objects {
+			if f.matches(object, r) && r.URL.Query().Get("offset") == "0" {
+				matching = append(matching, object)
+			}
+		}
+		json.NewEncoder(w).Encode(matching)
+	case r.Method == "POST" && strings.HasPrefix(r.URL.Path, objectsPath):
+		var object map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&object)
+		f.nextID++
+		object[":ID"] = fmt.Sprintf("id%d", f.nextID)
+		f.objects[object[":ID"].(string)] = object
+		json.NewEncoder(w).Encode(map[string]interface{}{"objects": []interface{}{map[string]interface{}{":ID": object[":ID"]}}})
+	case r.Method == "PATCH" && f.objects[id] != nil:
+		var changes map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&changes)
+		f.patches = append(f.patches, changes)
+		for key, value := range changes {
+			if value == nil {
+				delete(f.objects[id], key)
+			} else {
+				f.objects[id][key] = value
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"message": "ok"})
+	case r.Method == "DELETE" && f.objects[id] != nil:
+		delete(f.objects, id)
+		json.NewEncoder(w).Encode(map[string]interface{}{"message": "ok"})
+	case r.URL.Path == "/site/thruk/r/config/save":
+		f.saves++
+		json.NewEncoder(w).Encode(map[string]interface{}{"message": "ok"})
+	case r.URL.Path == "/site/thruk/r/config/check" || r.URL.Path == "/site/thruk/r/config/reload":
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"failed": false}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeThruk) matches(object map[string]interface{}, r *http.Request) bool {
+	for key, values := range r.URL.Query() {
+		if key == "limit" || key == "offset" {
+			continue
+		}
+		switch value := object[key].(type) {
+		case string:
+			if value != values[0] {
+				return false
+			}
+		case []interface{}:
+			found := false
+			for _, v := range value {
+				found = found || v == values[0]
+			}
+			if !found {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func startFakeThruk(objects ...map[string]interface{}) (*fakeThruk, *providerData, func()) {
+	fake := &fakeThruk{objects: map[string]map[string]interface{}{}}
+	for _, object := range objects {
+		fake.objects[object[":ID"].(string)] = object
+	}
+	server := httptest.NewServer(fake)
+	data := &providerData{thruk: thruk.NewThruk(server.URL, "site", "user", "pass", false), saveAndReload: true}
+	return fake, data, server.Close
+}
+
+func objectTypeNamed(name string) objectType {
+	for _, o := range objectTypes {
+		if o.name == name {
+			return o
+		}
+	}
+	panic(name)
+}
+
+func newResource(t *testing.T, name string, data *providerData) (*objectResource, tfsdk.State) {
+	t.Helper()
+	r := &objectResource{objectType: objectTypeNamed(name), provider: data}
+	var resp resource.SchemaResponse
+	r.Schema(context.Background(), resource.SchemaRequest{}, &resp)
+	assert.Assert(t, !resp.Diagnostics.HasError(), resp.Diagnostics)
+	raw := tftypes.NewValue(resp.Schema.Type().TerraformType(context.Background()), nil)
+	return r, tfsdk.State{Schema: resp.Schema, Raw: raw}
+}
+
+// withValues returns a copy of state with the given attributes set.
+func withValues(t *testing.T, state tfsdk.State, values map[string]attr.Value) tfsdk.State {
+	t.Helper()
+	for name, value := range values {
+		diags := state.SetAttribute(context.Background(), path.Root(name), value)
+		assert.Assert(t, !diags.HasError(), diags)
+	}
+	return state
+}
+
+func stringList(values ...string) types.List {
+	list, _ := types.ListValueFrom(context.Background(), types.StringType, values)
+	return list
+}
+
+func stateString(t *testing.T, state tfsdk.State, name string) types.String {
+	t.Helper()
+	var value types.String
+	assert.Assert(t, !state.GetAttribute(context.Background(), path.Root(name), &value).HasError())
+	return value
+}
+
+func Test_objectTypes(t *testing.T) {
+	t.Run("Every object type has valid resource and data source schemas", func(t *testing.T) {
+		ctx := context.Background()
+		for _, o := range objectTypes {
+			var resourceSchema resource.SchemaResponse
+			(&objectResource{objectType: o}).Schema(ctx, resource.SchemaRequest{}, &resourceSchema)
+			assert.Assert(t, !resourceSchema.Schema.ValidateImplementation(ctx).HasError(), o.name)
+			var dataSourceSchema datasource.SchemaResponse
+			(&objectDataSource{objectType: o}).Schema(ctx, datasource.SchemaRequest{}, &dataSourceSchema)
+			assert.Assert(t, !dataSourceSchema.Schema.ValidateImplementation(ctx).HasError(), o.name)
+			for _, key := range o.key {
+				_, ok := resourceSchema.Schema.Attributes[key]
+				assert.Assert(t, ok, "%s has no key attribute %s", o.name, key)
+			}
+		}
+	})
+	t.Run("Attributes that are not Terraform names are renamed", func(t *testing.T) {
+		var names []string
+		for _, a := range objectTypeNamed("host").attributes() {
+			names = append(names, a.name)
+		}
+		joined := strings.Join(names, " ")
+		assert.Assert(t, strings.Contains(joined, "coords_2d"))
+		assert.Assert(t, strings.Contains(joined, "worker"))
+		assert.Assert(t, strings.Contains(joined, "file"))
+		assert.Assert(t, !strings.Contains(joined, ":"))
+	})
+}
+
+func Test_objectResource(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Create stores the plan and saves and reloads", func(t *testing.T) {
+		fake, data, closeServer := startFakeThruk()
+		defer closeServer()
+		r, empty := newResource(t, "host", data)
+		plan := withValues(t, empty, map[string]attr.Value{
+			"file":      types.StringValue("hosts.cfg"),
+			"host_name": types.StringValue("web01"),
+			"use":       stringList("generic-host"),
+		})
+
+		resp := resource.CreateResponse{State: empty}
+		r.Create(ctx, resource.CreateRequest{Plan: tfsdk.Plan(plan)}, &resp)
+		assert.Assert(t, !resp.Diagnostics.HasError(), resp.Diagnostics)
+
+		assert.Equal(t, stateString(t, resp.State, "id").ValueString(), "id1")
+		assert.Equal(t, fake.objects["id1"]["host_name"], "web01")
+		assert.Equal(t, fake.objects["id1"][":TYPE"], "host")
+		assert.Equal(t, fake.saves, 1)
+	})
+	t.Run("Update patches only the changed attributes", func(t *testing.T) {
+		fake, data, closeServer := startFakeThruk(map[string]interface{}{
+			":ID": "h1", ":TYPE": "host", ":FILE": "hosts.cfg", "host_name": "web01",
+			"alias": "Web", "address": "10.0.0.1", "_OWNER": "ops",
+		})
+		defer closeServer()
+		r, empty := newResource(t, "host", data)
+		state := withValues(t, empty, map[string]attr.Value{
+			"id": types.StringValue("h1"), "file": types.StringValue("hosts.cfg"),
+			"host_name": types.StringValue("web01"), "alias": types.StringValue("Web"), "address": types.StringValue("10.0.0.1"),
+		})
+		plan := withValues(t, state, map[string]attr.Value{
+			"alias": types.StringNull(), "address": types.StringValue("10.0.0.2"),
+		})
+
+		resp := resource.UpdateResponse{State: state}
+		r.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan(plan), State: state}, &resp)
+		assert.Assert(t, !resp.Diagnostics.HasError(), resp.Diagnostics)
+
+		assert.DeepEqual(t, fake.patches, []map[string]interface{}{{"alias": nil, "address": "10.0.0.2"}})
+		assert.DeepEqual(t, fake.objects["h1"], map[string]interface{}{
+			":ID": "h1", ":TYPE": "host", ":FILE": "hosts.cfg", "host_name": "web01",
+			"address": "10.0.0.2", "_OWNER": "ops",
+		})
+		assert.Equal(t, fake.saves, 1)
+	})
+	t.Run("Timeperiod exceptions are patched one by one", func(t *testing.T) {
+		fake, data, closeServer := startFakeThruk(map[string]interface{}{
+			":ID": "t1", ":TYPE": "timeperiod", ":FILE": "tp.cfg", "timeperiod_name": "holidays",
+			"2026-12-25": "00:00-24:00", "2026-12-26": "00:00-24:00",
+		})
+		defer closeServer()
+		r, empty := newResource(t, "timeperiod", data)
+		before, _ := types.MapValueFrom(ctx, types.StringType, map[string]string{"2026-12-25": "00:00-24:00", "2026-12-26": "00:00-24:00"})
+		after, _ := types.MapValueFrom(ctx, types.StringType, map[string]string{"2026-12-25": "00:00-24:00", "2027-01-01": "00:00-12:00"})
+		state := withValues(t, empty, map[string]attr.Value{
+			"id": types.StringValue("t1"), "file": types.StringValue("tp.cfg"),
+			"timeperiod_name": types.StringValue("holidays"), "exceptions": before,
+		})
+		plan := withValues(t, state, map[string]attr.Value{"exceptions": after})
+
+		resp := resource.UpdateResponse{State: state}
+		r.Update(ctx, resource.UpdateRequest{Plan: tfsdk.Plan(plan), State: state}, &resp)
+		assert.Assert(t, !resp.Diagnostics.HasError(), resp.Diagnostics)
+		assert.DeepEqual(t, fake.patches, []map[string]interface{}{{"2026-12-26": nil, "2027-01-01": "00:00-12:00"}})
+	})
+	t.Run("Read shows changes made in Thruk and drops deleted objects", func(t *testing.T) {
+		fake, data, closeServer := startFakeThruk(map[string]interface{}{
+			":ID": "s1", ":TYPE": "servicegroup", ":FILE": "groups.cfg", "servicegroup_name": "web",
+			"members": []interface{}{"web01", "http", "web02", "http"},
+		})
+		defer closeServer()
+		r, empty := newResource(t, "servicegroup", data)
+		state := withValues(t, empty, map[string]attr.Value{
+			"id": types.StringValue("s1"), "file": types.StringValue("groups.cfg"),
+			"servicegroup_name": types.StringValue("web"), "alias": types.StringValue(""),
+		})
+
+		resp := resource.ReadResponse{State: state}
+		r.Read(ctx, resource.ReadRequest{State: state}, &resp)
+		assert.Assert(t, !resp.Diagnostics.HasError(), resp.Diagnostics)
+		var members []serviceRef
+		assert.Assert(t, !resp.State.GetAttribute(ctx, path.Root("members"), &members).HasError())
+		assert.DeepEqual(t, members, []serviceRef{{"web01", "http"}, {"web02", "http"}})
+		assert.Equal(t, stateString(t, resp.State, "alias"), types.StringValue(""))
+
+		delete(fake.objects, "s1")
+		resp = resource.ReadResponse{State: state}
+		r.Read(ctx, resource.ReadRequest{State: state}, &resp)
+		assert.Assert(t, !resp.Diagnostics.HasError(), resp.Diagnostics)
+		assert.Assert(t, resp.State.Raw.IsNull())
+	})
+	t.Run("Import accepts an :ID or the key", func(t *testing.T) {
+		_, data, closeServer := startFakeThruk(map[string]interface{}{
+			":ID": "v1", ":TYPE": "service", ":FILE": "services.cfg",
+			"host_name": []interface{}{"web01"}, "service_description": "http/health",
+		})
+		defer closeServer()
+		r, empty := newResource(t, "service", data)
+
+		for _, id := range []string{"v1", "web01/http/health"} {
+			resp := resource.ImportStateResponse{State: empty}
+			r.ImportState(ctx, resource.ImportStateRequest{ID: id}, &resp)
+			assert.Assert(t, !resp.Diagnostics.HasError(), resp.Diagnostics)
+			assert.Equal(t, stateString(t, resp.State, "id").ValueString(), "v1")
+		}
+
+		resp := resource.ImportStateResponse{State: empty}
+		r.ImportState(ctx, resource.ImportStateRequest{ID: "web01/ping"}, &resp)
+		assert.Assert(t, resp.Diagnostics.HasError())
+	})
+	t.Run("Delete removes the object", func(t *testing.T) {
+		fake, data, closeServer := startFakeThruk(map[string]interface{}{":ID": "c1", ":TYPE": "command", "command_name": "check_ping"})
+		defer closeServer()
+		r, empty := newResource(t, "command", data)
+		state := withValues(t, empty, map[string]attr.Value{"id": types.StringValue("c1")})
+
+		resp := resource.DeleteResponse{State: state}
+		r.Delete(ctx, resource.DeleteRequest{State: state}, &resp)
+		assert.Assert(t, !resp.Diagnostics.HasError(), resp.Diagnostics)
+		assert.Equal(t, len(fake.objects), 0)
+		assert.Equal(t, fake.saves, 1)
+	})
+}
+
+func Test_objectDataSource(t *testing.T) {
+	ctx := context.Background()
+	_, data, closeServer := startFakeThruk(map[string]interface{}{
+		":ID": "h1", ":TYPE": "host", ":FILE": "hosts.cfg", "host_name": "web01", "address": "10.0.0.1",
+	})
+	defer closeServer()
+	d := &objectDataSource{objectType: objectTypeNamed("host"), provider: data}
+	var schemaResp datasource.SchemaResponse
+	d.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	raw := tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)
+
+	for _, lookup := range []map[string]attr.Value{
+		{"id": types.StringValue("h1")},
+		{"host_name": types.StringValue("web01")},
+	} {
+		config := tfsdk.State{Schema: schemaResp.Schema, Raw: raw}
+		for name, value := range lookup {
+			assert.Assert(t, !config.SetAttribute(ctx, path.Root(name), value).HasError())
+		}
+		resp := datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: raw}}
+		d.Read(ctx, datasource.ReadRequest{Config: tfsdk.Config(config)}, &resp)
+		assert.Assert(t, !resp.Diagnostics.HasError(), resp.Diagnostics)
+		assert.Equal(t, stateString(t, resp.State, "id").ValueString(), "h1")
+		assert.Equal(t, stateString(t, resp.State, "address").ValueString(), "10.0.0.1")
+	}
+}
+
+func Test_providerData_commit(t *testing.T) {
+	t.Run("Changes finished during a reload share the next one", func(t *testing.T) {
+		fake, data, closeServer := startFakeThruk()
+		defer closeServer()
+		fake.Lock()
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Check(t, data.commit())
+			}()
+		}
+		// the first commit waits for the server while the others queue
+		for atomic.LoadInt64(&data.changes) < 5 {
+			runtime.Gosched()
+		}
+		fake.Unlock()
+		wg.Wait()
+		assert.Assert(t, fake.saves <= 2, fake.saves)
+	})
+	t.Run("Nothing is saved when save_and_reload is off", func(t *testing.T) {
+		fake, data, closeServer := startFakeThruk()
+		defer closeServer()
+		data.saveAndReload = false
+		assert.NilError(t, data.commit())
+		assert.Equal(t, fake.saves, 0)
+	})
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	thruk "gitlab.com/roviluca/thruk-go"
+)
+
+// objectResource manages one Thruk object of its type. Changes are sent as
+// patches of the changed attributes, so attributes set outside Terraform,
+// like custom variables, are kept.
+type objectResource struct {
+	objectType
+	provider *providerData
+}
+
+var (
+	_ resource.ResourceWithConfigure   = &objectResource{}
+	_ resource.ResourceWithImportState = &objectResource{}
+)
+
+func (r *objectResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + r.name
+}
+
+func (r *objectResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "The `:ID` Thruk assigned to the object.",
+			PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+		},
+	}
+	for _, a := range r.attributes() {
+		description := fmt.Sprintf("The `%s` attribute.", a.thrukKey)
+		switch {
+		case a.name == "file":
+			attributes[a.name] = schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The configuration file of the object, changing it replaces the object.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			}
+		case a.kind == kindString:
+			attributes[a.name] = schema.StringAttribute{Optional: true, MarkdownDescription: description}
+		case a.kind == kindList:
+			attributes[a.name] = schema.ListAttribute{Optional: true, ElementType: types.StringType, MarkdownDescription: description}
+		case a.kind == kindServiceRefs:
+			attributes[a.name] = schema.ListNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "The services in the group.",
+				NestedObject: schema.NestedAttributeObject{Attributes: map[string]schema.Attribute{
+					"host":    schema.StringAttribute{Required: true},
+					"service": schema.StringAttribute{Required: true},
+				}},
+			}
+		case a.kind == kindExceptions:
+			attributes[a.name] = schema.MapAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Exceptions keyed by their date, like `2026-12-25` or `day 1 - 15`, with their time ranges.",
+			}
+		}
+	}
+	resp.Schema = schema.Schema{
+		MarkdownDescription: fmt.Sprintf("A Thruk %s. It can be imported by its `:ID` or by %s.", r.name, r.importKey()),
+		Attributes:          attributes,
+	}
+}
+
+func (r *objectResource) importKey() string {
+	return "`" + strings.Join(r.key, "/") + "`"
+}
+
+func (r *objectResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData != nil {
+		r.provider = req.ProviderData.(*providerData)
+	}
+}
+
+func (r *objectResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	plan, diags := r.values(ctx, req.Plan.GetAttribute)
+	resp.Diagnostics.Append(diags...)
+	object, diags := r.toObject(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	id, err := r.create(r.provider.thruk, object)
+	if err != nil {
+		resp.Diagnostics.AddError("Creating "+r.name, err.Error())
+		return
+	}
+	resp.Diagnostics.Append(setValues(ctx, &resp.State, id, plan)...)
+	r.commit(&resp.Diagnostics)
+}
+
+func (r *objectResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var id types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	prior, diags := r.values(ctx, req.State.GetAttribute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	object, err := r.get(r.provider.thruk, id.ValueString())
+	if errors.Is(err, thruk.ErrorObjectNotFound) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Reading "+r.name, err.Error())
+		return
+	}
+	values, diags := r.fromObject(ctx, object, prior)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(setValues(ctx, &resp.State, id.ValueString(), values)...)
+}
+
+func (r *objectResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var id types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	plan, diags := r.values(ctx, req.Plan.GetAttribute)
+	resp.Diagnostics.Append(diags...)
+	state, diags := r.values(ctx, req.State.GetAttribute)
+	resp.Diagnostics.Append(diags...)
+	changes, diags := r.patch(ctx, plan, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if len(changes) > 0 {
+		if err := r.provider.thruk.PatchConfigObject(id.ValueString(), changes); err != nil {
+			resp.Diagnostics.AddError("Updating "+r.name, err.Error())
+			return
+		}
+	}
+	resp.Diagnostics.Append(setValues(ctx, &resp.State, id.ValueString(), plan)...)
+	if len(changes) > 0 {
+		r.commit(&resp.Diagnostics)
+	}
+}
+
+func (r *objectResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var id types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	err := r.delete(r.provider.thruk, id.ValueString())
+	if err != nil && !errors.Is(err, thruk.ErrorObjectNotFound) {
+		resp.Diagnostics.AddError("Deleting "+r.name, err.Error())
+		return
+	}
+	r.commit(&resp.Diagnostics)
+}
+
+// ImportState accepts the :ID of an object or its key, like web01 for a
+// host or web01/http for a service.
+func (r *objectResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	object, err := r.get(r.provider.thruk, req.ID)
+	if errors.Is(err, thruk.ErrorObjectNotFound) {
+		key := strings.SplitN(req.ID, "/", len(r.key))
+		if len(key) != len(r.key) {
+			resp.Diagnostics.AddError("Importing "+r.name, fmt.Sprintf("%q is neither an :ID nor %s", req.ID, r.importKey()))
+			return
+		}
+		object, err = r.getByKey(r.provider.thruk, key)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Importing "+r.name, fmt.Sprintf("%s: %s", req.ID, err))
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), objectID(object))...)
+}
+
+// commit saves and reloads the change, a failure is reported on the
+// resource after its state was stored.
+func (r *objectResource) commit(diags *diag.Diagnostics) {
+	if err := r.provider.commit(); err != nil {
+		diags.AddError("Saving and reloading the Thruk configuration", err.Error())
+	}
+}
+
+func setValues(ctx context.Context, state *tfsdk.State, id string, values map[string]attr.Value) diag.Diagnostics {
+	diags := state.SetAttribute(ctx, path.Root("id"), id)
+	for name, value := range values {
+		diags.Append(state.SetAttribute(ctx, path.Root(name), value)...)
+	}
+	return diags
+}
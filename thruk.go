@@ -2,19 +2,22 @@ package thruk
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"net/url"
 	"time"
 )
 
 var ErrorInvalidInput = errors.New("[ERROR] invalid input")
 var ErrorNeedFileAndType = errors.New("[ERROR] FILE and TYPE must not be empty")
 var ErrorObjectNotFound = errors.New("[ERROR] Object not found")
+var ErrorNeedID = errors.New("[ERROR] ID must not be empty")
+var ErrorAmbiguousName = errors.New("[ERROR] more than one object matches the given name")
 
 type Thruk struct {
 	URL      string
@@ -22,6 +25,13 @@ type Thruk struct {
 	username string
 	password string
 	SiteName string
+	// PageSize is the number of objects list methods fetch per request,
+	// 1000 when not set.
+	PageSize int
+	cache    *configCache
+	limits   *requestLimits
+	observer Observer
+	logger   Logger
 }
 
 type thrukResponse struct {
@@ -104,6 +114,15 @@ type ConfigObject struct {
 	ServicegroupName            string   `json:"servicegroup_name,omitempty"`
 	Servicegroups               []string `json:"servicegroups,omitempty"`
 	ServiceDescription          string   `json:"service_description,omitempty"`
+	HostgroupName               string   `json:"hostgroup_name,omitempty"`
+	HostgroupMembers            []string `json:"hostgroup_members,omitempty"`
+	Members                     []string `json:"members,omitempty"`
+	ServicegroupMembers         []string `json:"servicegroup_members,omitempty"`
+	ContactName                 string   `json:"contact_name,omitempty"`
+	Contactgroups               []string `json:"contactgroups,omitempty"`
+	Email                       string   `json:"email,omitempty"`
+	Pager                       string   `json:"pager,omitempty"`
+	Exclude                     []string `json:"exclude,omitempty"`
 }
 
 type reloadResponse []struct {
@@ -131,33 +150,100 @@ func newClient() *http.Client {
 }
 
 func (t Thruk) GetURL(URL string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", t.URL+URL, nil)
+	return t.send("GET", URL, nil)
+}
+
+func (t Thruk) PostURL(URL string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest("POST", t.URL+URL, body)
 	if err != nil {
-		log.Fatalf("Error: %s", err)
+		return nil, err
 	}
 	req.SetBasicAuth(t.username, t.password)
-	resp, err := t.client.Do(req)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.do(req)
 	if err != nil {
-		log.Fatalf("Error: %s", err)
+		return nil, err
 	}
+	t.cache.invalidate("POST", URL)
+
 	return resp, err
 }
 
-func (t Thruk) PostURL(URL string, body io.Reader) (*http.Response, error) {
-	req, err := http.NewRequest("POST", t.URL+URL, body)
+func (t Thruk) PutURL(URL string, body io.Reader) (*http.Response, error) {
+	return t.send("PUT", URL, body)
+}
+
+func (t Thruk) PatchURL(URL string, body io.Reader) (*http.Response, error) {
+	return t.send("PATCH", URL, body)
+}
+
+// send performs an authenticated request.
+func (t Thruk) send(method, URL string, body io.Reader) (*http.Response, error) {
+	return t.sendContext(context.Background(), method, URL, body)
+}
+
+func (t Thruk) sendContext(ctx context.Context, method, URL string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, t.URL+URL, body)
 	if err != nil {
-		log.Fatalf("Error: %s", err)
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 	req.SetBasicAuth(t.username, t.password)
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := t.client.Do(req)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := t.do(req)
+	if err == nil {
+		t.cache.invalidate(method, URL)
+	}
+	return resp, err
+}
+
+// getJSON fetches URL and decodes the JSON response into v.
+func (t Thruk) getJSON(URL string, v interface{}) error {
+	return t.getJSONContext(context.Background(), URL, v)
+}
+
+func (t Thruk) getJSONContext(ctx context.Context, URL string, v interface{}) error {
+	resp, err := t.sendContext(ctx, "GET", URL, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return errors.New(resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// sendJSON sends body encoded as JSON and turns error statuses into errors.
+func (t Thruk) sendJSON(method, URL string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(bodyBytes)
+	}
+	resp, err := t.send(method, URL, reader)
 	if err != nil {
-		log.Fatalf("Error: %s", err)
 		return nil, err
 	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, errors.New(resp.Status)
+	}
+	return resp, nil
+}
 
-	return resp, err
+func (t Thruk) ListConfigObjects(filter url.Values) ([]ConfigObject, error) {
+	var objects []ConfigObject
+	err := t.findConfigObjects(filter, &objects)
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
 }
 
 func (t Thruk) GetConfigObject(id string) (object ConfigObject, err error) {
@@ -165,12 +251,10 @@ func (t Thruk) GetConfigObject(id string) (object ConfigObject, err error) {
 	if id == "" {
 		return object, ErrorInvalidInput
 	}
-	resp, err := t.GetURL("/" + t.SiteName + "/thruk/r/config/objects?:ID=" + id)
-	defer resp.Body.Close()
-	failOnError(err)
-
-	err = json.NewDecoder(resp.Body).Decode(&configObjects)
-	failOnError(err)
+	err = t.findConfigObjects(url.Values{":ID": {id}}, &configObjects)
+	if err != nil {
+		return object, err
+	}
 	if len(configObjects) == 0 {
 		return ConfigObject{}, ErrorObjectNotFound
 	}
@@ -204,6 +288,86 @@ func (t Thruk) CreateConfigObject(object ConfigObject) (id string, err error) {
 	return thrukResp.Objects[0].ID, err
 }
 
+func (t Thruk) UpdateConfigObject(object ConfigObject) error {
+	if object.ID == "" {
+		return ErrorNeedID
+	}
+	return t.replaceConfigObject(object.ID, object)
+}
+
+// PatchConfigObject changes only the given attributes of an object. A nil
+// value removes the attribute.
+func (t Thruk) PatchConfigObject(id string, attributes map[string]interface{}) error {
+	if id == "" {
+		return ErrorNeedID
+	}
+	bodyBytes, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+	resp, err := t.PatchURL("/"+t.SiteName+"/thruk/r/config/objects/"+id, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
+
+// createConfigObject posts any of the typed objects and returns the new ID.
+func (t Thruk) createConfigObject(object interface{}) (string, error) {
+	return t.createConfigObjectContext(context.Background(), object)
+}
+
+func (t Thruk) createConfigObjectContext(ctx context.Context, object interface{}) (string, error) {
+	bodyBytes, err := json.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+	resp, err := t.sendContext(ctx, "POST", "/"+t.SiteName+"/thruk/r/config/objects/", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", errors.New(resp.Status)
+	}
+
+	thrukResp := thrukResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&thrukResp)
+	if err != nil {
+		return "", err
+	}
+	if len(thrukResp.Objects) == 0 {
+		return "", errors.New("object not created")
+	}
+	return thrukResp.Objects[0].ID, nil
+}
+
+// replaceConfigObject overwrites all attributes of the object with the given
+// ID, so attributes missing from object are removed.
+func (t Thruk) replaceConfigObject(id string, object interface{}) error {
+	return t.replaceConfigObjectContext(context.Background(), id, object)
+}
+
+func (t Thruk) replaceConfigObjectContext(ctx context.Context, id string, object interface{}) error {
+	bodyBytes, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	resp, err := t.sendContext(ctx, "POST", "/"+t.SiteName+"/thruk/r/config/objects/"+id, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
+
 func (t Thruk) DiscardConfigs() error {
 	resp, err := t.PostURL("/"+t.SiteName+"/thruk/r/config/discard", nil)
 	if err != nil {
@@ -246,6 +410,18 @@ func (t Thruk) ReloadConfigs() error {
 	return nil
 }
 
+// SaveAndReloadConfigs writes staged changes to disk, verifies the result and
+// reloads the core. The reload is skipped when the check fails.
+func (t Thruk) SaveAndReloadConfigs() error {
+	if err := t.SaveConfigs(); err != nil {
+		return err
+	}
+	if !t.CheckConfig() {
+		return errors.New("config check failed, not reloading")
+	}
+	return t.ReloadConfigs()
+}
+
 func (t Thruk) DeleteConfigObject(id string) error {
 	URL := "/" + t.SiteName + "/thruk/r/config/objects/" + id
 	err := t.DeleteURL(URL)
@@ -256,18 +432,24 @@ func (t Thruk) DeleteConfigObject(id string) error {
 	return nil
 }
 
-func (t Thruk) DeleteURL(URL string) error {
-	req, err := http.NewRequest("DELETE", t.URL+URL, nil)
+func (t Thruk) deleteConfigObjectContext(ctx context.Context, id string) error {
+	resp, err := t.sendContext(ctx, "DELETE", "/"+t.SiteName+"/thruk/r/config/objects/"+id, nil)
 	if err != nil {
-		log.Fatalf("Error: %s", err)
 		return err
 	}
-	req.SetBasicAuth(t.username, t.password)
-	resp, err := t.client.Do(req)
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
+
+func (t Thruk) DeleteURL(URL string) error {
+	resp, err := t.send("DELETE", URL, nil)
 	if err != nil {
-		log.Fatalf("Error: %s", err)
 		return err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode >= 400 {
 		return errors.New(resp.Status)
 	}
@@ -288,18 +470,16 @@ func (t Thruk) CheckConfig() bool {
 	return !checkResult[0].Failed
 }
 
-func failOnError(err error) {
-	if err != nil {
-		log.Fatalf("Error: %s", err)
-	}
-}
-func NewThruk(URL, SiteName, username, password string, skipTLS bool) *Thruk {
+// Option configures a client created by NewThruk.
+type Option func(*Thruk)
+
+func NewThruk(URL, SiteName, username, password string, skipTLS bool, options ...Option) *Thruk {
 	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: skipTLS,
 		},
 	}
-	return &Thruk{
+	t := &Thruk{
 		URL:      URL,
 		SiteName: SiteName,
 		client: http.Client{
@@ -309,4 +489,8 @@ func NewThruk(URL, SiteName, username, password string, skipTLS bool) *Thruk {
 		username: username,
 		password: password,
 	}
+	for _, option := range options {
+		option(t)
+	}
+	return t
 }
@@ -268,4 +268,24 @@ func Test_thruk_client_DeleteConfigObject(t *testing.T) {
 		_, err := thruk.GetConfigObject(id)
 		assert.Error(t, err, "[ERROR] Object not found")
 	})
+	t.Run("patch object changes only the given attributes", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateConfigObject(ConfigObject{
+			FILE:    "test.cfg",
+			TYPE:    "host",
+			Name:    "localhost",
+			Alias:   "localhost",
+			Address: "127.0.0.1",
+		})
+		assert.NilError(t, err)
+
+		err = thruk.PatchConfigObject(id, map[string]interface{}{"alias": "patched"})
+		assert.NilError(t, err)
+
+		patched, err := thruk.GetConfigObject(id)
+		assert.NilError(t, err)
+		assert.Equal(t, patched.Alias, "patched")
+		assert.Equal(t, patched.Address, "127.0.0.1")
+	})
 }
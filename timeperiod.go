@@ -0,0 +1,137 @@
+package thruk
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+type Timeperiod struct {
+	FILE           string   `json:":FILE"`
+	ID             string   `json:":ID,omitempty"`
+	PEERKEY        string   `json:":PEER_KEY,omitempty"`
+	READONLY       int      `json:":READONLY,omitempty"`
+	TYPE           string   `json:":TYPE"`
+	Alias          string   `json:"alias,omitempty"`
+	Exclude        []string `json:"exclude,omitempty"`
+	Name           string   `json:"name,omitempty"`
+	Register       string   `json:"register,omitempty"`
+	TimeperiodName string   `json:"timeperiod_name,omitempty"`
+	Use            []string `json:"use,omitempty"`
+	Monday         string   `json:"monday,omitempty"`
+	Tuesday        string   `json:"tuesday,omitempty"`
+	Wednesday      string   `json:"wednesday,omitempty"`
+	Thursday       string   `json:"thursday,omitempty"`
+	Friday         string   `json:"friday,omitempty"`
+	Saturday       string   `json:"saturday,omitempty"`
+	Sunday         string   `json:"sunday,omitempty"`
+	// Exceptions holds date exceptions like "2026-12-25" or "day 1 - 15",
+	// keyed by the attribute name Thruk reports them under. The full
+	// definition is the key followed by the value.
+	Exceptions map[string]string `json:"-"`
+}
+
+type timeperiodAttributes Timeperiod
+
+var timeperiodAttributeNames = func() map[string]bool {
+	names := map[string]bool{}
+	fields := reflect.TypeOf(timeperiodAttributes{})
+	for i := 0; i < fields.NumField(); i++ {
+		name := strings.Split(fields.Field(i).Tag.Get("json"), ",")[0]
+		names[name] = true
+	}
+	return names
+}()
+
+func (tp Timeperiod) MarshalJSON() ([]byte, error) {
+	bodyBytes, err := json.Marshal(timeperiodAttributes(tp))
+	if err != nil || len(tp.Exceptions) == 0 {
+		return bodyBytes, err
+	}
+	attributes := map[string]interface{}{}
+	if err := json.Unmarshal(bodyBytes, &attributes); err != nil {
+		return nil, err
+	}
+	for key, value := range tp.Exceptions {
+		attributes[key] = value
+	}
+	return json.Marshal(attributes)
+}
+
+func (tp *Timeperiod) UnmarshalJSON(data []byte) error {
+	var attributes timeperiodAttributes
+	if err := json.Unmarshal(data, &attributes); err != nil {
+		return err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	*tp = Timeperiod(attributes)
+	for key, value := range all {
+		text, ok := value.(string)
+		if !ok || timeperiodAttributeNames[key] || strings.HasPrefix(key, ":") || strings.HasPrefix(key, "_") {
+			continue
+		}
+		if tp.Exceptions == nil {
+			tp.Exceptions = map[string]string{}
+		}
+		tp.Exceptions[key] = text
+	}
+	return nil
+}
+
+func (t Thruk) GetTimeperiod(id string) (Timeperiod, error) {
+	var timeperiods []Timeperiod
+	if id == "" {
+		return Timeperiod{}, ErrorInvalidInput
+	}
+	err := t.findConfigObjects(url.Values{":TYPE": {"timeperiod"}, ":ID": {id}}, &timeperiods)
+	if err != nil {
+		return Timeperiod{}, err
+	}
+	if len(timeperiods) == 0 {
+		return Timeperiod{}, ErrorObjectNotFound
+	}
+
+	return timeperiods[0], nil
+}
+
+func (t Thruk) GetTimeperiodByName(name string) (Timeperiod, error) {
+	var timeperiods []Timeperiod
+	if name == "" {
+		return Timeperiod{}, ErrorInvalidInput
+	}
+	err := t.findConfigObjects(url.Values{":TYPE": {"timeperiod"}, "timeperiod_name": {name}}, &timeperiods)
+	if err != nil {
+		return Timeperiod{}, err
+	}
+	if len(timeperiods) == 0 {
+		return Timeperiod{}, ErrorObjectNotFound
+	}
+	if len(timeperiods) > 1 {
+		return Timeperiod{}, ErrorAmbiguousName
+	}
+
+	return timeperiods[0], nil
+}
+
+func (t Thruk) CreateTimeperiod(timeperiod Timeperiod) (string, error) {
+	if timeperiod.FILE == "" || timeperiod.TYPE == "" {
+		return "", ErrorNeedFileAndType
+	}
+	return t.createConfigObject(timeperiod)
+}
+
+func (t Thruk) UpdateTimeperiod(timeperiod Timeperiod) error {
+	if timeperiod.ID == "" {
+		return ErrorNeedID
+	}
+	return t.replaceConfigObject(timeperiod.ID, timeperiod)
+}
+
+func (t Thruk) DeleteTimeperiod(id string) error {
+	URL := "/" + t.SiteName + "/thruk/r/config/objects/" + id
+	return t.DeleteURL(URL)
+}
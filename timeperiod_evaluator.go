@@ -0,0 +1,449 @@
+package thruk
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeperiodEvaluator answers whether a point in time lies within a
+// timeperiod, following the Nagios rules: date exceptions take precedence
+// over the weekday definitions (calendar dates first, then month dates,
+// days of the month, weekdays of a given month and weekdays of every
+// month) and excluded timeperiods are subtracted at the end.
+type TimeperiodEvaluator struct {
+	name       string
+	location   *time.Location
+	weekdays   [7][]secondRange
+	exceptions []timeperiodException
+	excludes   []*TimeperiodEvaluator
+}
+
+// secondRange is a time range in seconds since midnight, end excluded.
+type secondRange struct {
+	start int
+	end   int
+}
+
+type exceptionKind int
+
+const (
+	calendarDate exceptionKind = iota // 2026-12-25
+	monthDate                         // december 25
+	monthDay                          // day 25
+	monthWeekDay                      // thursday 4 november
+	weekDay                           // thursday 4
+)
+
+type dateSpec struct {
+	year  int
+	month time.Month
+	// day of the month or, for weekdays, the occurrence in the month.
+	// Negative values count from the end of the month.
+	day     int
+	weekday time.Weekday
+}
+
+type timeperiodException struct {
+	definition string
+	kind       exceptionKind
+	start      dateSpec
+	end        dateSpec
+	skip       int
+	// openEnd marks calendar dates with a skip but no end, like
+	// "2026-01-01 / 7", which repeat forever.
+	openEnd bool
+	ranges  []secondRange
+}
+
+// timeperiodSearchDays bounds NextTransition; four years cover every
+// yearly rule including February 29.
+const timeperiodSearchDays = 4*366 + 1
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+var monthNames = map[string]time.Month{
+	"january":   time.January,
+	"february":  time.February,
+	"march":     time.March,
+	"april":     time.April,
+	"may":       time.May,
+	"june":      time.June,
+	"july":      time.July,
+	"august":    time.August,
+	"september": time.September,
+	"october":   time.October,
+	"november":  time.November,
+	"december":  time.December,
+}
+
+var (
+	calendarDatePattern = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	timeRangePattern    = regexp.MustCompile(`^\d{1,2}:\d{2}-`)
+	dateRangeSeparator  = regexp.MustCompile(`\s+-\s+`)
+)
+
+// NewTimeperiodEvaluator prepares tp for evaluation in loc, time.Local when
+// nil. Timeperiods named in exclude are looked up by timeperiod_name in
+// others.
+func NewTimeperiodEvaluator(tp Timeperiod, loc *time.Location, others ...Timeperiod) (*TimeperiodEvaluator, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	byName := map[string]Timeperiod{}
+	for _, other := range others {
+		byName[other.TimeperiodName] = other
+	}
+	return newTimeperiodEvaluator(tp, loc, byName, map[string]bool{})
+}
+
+func newTimeperiodEvaluator(tp Timeperiod, loc *time.Location, byName map[string]Timeperiod, visiting map[string]bool) (*TimeperiodEvaluator, error) {
+	e := &TimeperiodEvaluator{name: tp.TimeperiodName, location: loc}
+	if visiting[tp.TimeperiodName] {
+		return nil, fmt.Errorf("timeperiod %s excludes itself", tp.TimeperiodName)
+	}
+	visiting[tp.TimeperiodName] = true
+	defer delete(visiting, tp.TimeperiodName)
+
+	days := map[time.Weekday]string{
+		time.Sunday: tp.Sunday, time.Monday: tp.Monday, time.Tuesday: tp.Tuesday, time.Wednesday: tp.Wednesday,
+		time.Thursday: tp.Thursday, time.Friday: tp.Friday, time.Saturday: tp.Saturday,
+	}
+	for weekday, definition := range days {
+		ranges, err := parseTimeRanges(definition)
+		if err != nil {
+			return nil, fmt.Errorf("timeperiod %s: %s", tp.TimeperiodName, err)
+		}
+		e.weekdays[weekday] = ranges
+	}
+
+	for key, value := range tp.Exceptions {
+		exception, err := parseTimeperiodException(key + " " + value)
+		if err != nil {
+			return nil, fmt.Errorf("timeperiod %s: %s", tp.TimeperiodName, err)
+		}
+		e.exceptions = append(e.exceptions, exception)
+	}
+	sort.Slice(e.exceptions, func(i, j int) bool {
+		if e.exceptions[i].kind != e.exceptions[j].kind {
+			return e.exceptions[i].kind < e.exceptions[j].kind
+		}
+		return e.exceptions[i].definition < e.exceptions[j].definition
+	})
+
+	for _, name := range tp.Exclude {
+		excluded, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("timeperiod %s: excluded timeperiod %s not found", tp.TimeperiodName, name)
+		}
+		evaluator, err := newTimeperiodEvaluator(excluded, loc, byName, visiting)
+		if err != nil {
+			return nil, err
+		}
+		e.excludes = append(e.excludes, evaluator)
+	}
+	return e, nil
+}
+
+// parseTimeRanges parses "00:00-09:00,17:00-24:00".
+func parseTimeRanges(definition string) ([]secondRange, error) {
+	var ranges []secondRange
+	for _, part := range strings.Split(definition, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		bounds := strings.Split(part, "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid time range %q", part)
+		}
+		start, err := parseClock(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		if end < start {
+			return nil, fmt.Errorf("time range %q ends before it starts", part)
+		}
+		ranges = append(ranges, secondRange{start: start, end: end})
+	}
+	return ranges, nil
+}
+
+func parseClock(clock string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(clock), ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+	if hours < 0 || minutes < 0 || minutes > 59 || hours*60+minutes > 24*60 {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+	return hours*3600 + minutes*60, nil
+}
+
+// parseTimeperiodException parses a full exception line such as
+// "day 1 - 15 / 2 00:00-24:00".
+func parseTimeperiodException(definition string) (timeperiodException, error) {
+	exception := timeperiodException{definition: strings.TrimSpace(definition), skip: 1}
+	fields := strings.Fields(strings.ToLower(definition))
+	split := len(fields)
+	for i, field := range fields {
+		if timeRangePattern.MatchString(field) {
+			split = i
+			break
+		}
+	}
+	if split == 0 || split == len(fields) {
+		return exception, fmt.Errorf("invalid exception %q", definition)
+	}
+	ranges, err := parseTimeRanges(strings.Join(fields[split:], ""))
+	if err != nil {
+		return exception, err
+	}
+	exception.ranges = ranges
+
+	dates := strings.Join(fields[:split], " ")
+	if parts := strings.SplitN(dates, "/", 2); len(parts) == 2 {
+		skip, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || skip < 1 {
+			return exception, fmt.Errorf("invalid skip interval in %q", definition)
+		}
+		exception.skip = skip
+		dates = strings.TrimSpace(parts[0])
+	}
+
+	bounds := dateRangeSeparator.Split(dates, 2)
+	var startKind exceptionKind
+	exception.start, startKind, err = parseDateSpec(bounds[0])
+	if err != nil {
+		return exception, fmt.Errorf("invalid exception %q: %s", definition, err)
+	}
+	exception.kind = startKind
+	exception.end = exception.start
+	exception.openEnd = len(bounds) == 1 && startKind == calendarDate && exception.skip > 1
+	if len(bounds) == 2 {
+		if day, err := strconv.Atoi(bounds[1]); err == nil && startKind != calendarDate {
+			// "day 1 - 15" and "july 10 - 15" repeat the start
+			exception.end.day = day
+		} else {
+			var endKind exceptionKind
+			exception.end, endKind, err = parseDateSpec(bounds[1])
+			if err != nil || endKind != startKind {
+				return exception, fmt.Errorf("invalid exception %q: start and end differ", definition)
+			}
+		}
+	}
+	return exception, nil
+}
+
+func parseDateSpec(text string) (dateSpec, exceptionKind, error) {
+	if match := calendarDatePattern.FindStringSubmatch(text); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		day, _ := strconv.Atoi(match[3])
+		return dateSpec{year: year, month: time.Month(month), day: day}, calendarDate, nil
+	}
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return dateSpec{}, 0, fmt.Errorf("unknown date %q", text)
+	}
+	number, err := strconv.Atoi(fields[1])
+	if err != nil || number == 0 {
+		return dateSpec{}, 0, fmt.Errorf("unknown date %q", text)
+	}
+	month, isMonth := monthNames[fields[0]]
+	weekday, isWeekday := weekdayNames[fields[0]]
+	switch {
+	case fields[0] == "day" && len(fields) == 2:
+		return dateSpec{day: number}, monthDay, nil
+	case isMonth && len(fields) == 2:
+		return dateSpec{month: month, day: number}, monthDate, nil
+	case isWeekday && len(fields) == 2:
+		return dateSpec{weekday: weekday, day: number}, weekDay, nil
+	case isWeekday && len(fields) == 3:
+		if month, ok := monthNames[fields[2]]; ok {
+			return dateSpec{weekday: weekday, month: month, day: number}, monthWeekDay, nil
+		}
+	}
+	return dateSpec{}, 0, fmt.Errorf("unknown date %q", text)
+}
+
+// dayNumber counts days since the Unix epoch for a calendar date.
+func dayNumber(year int, month time.Month, day int) int {
+	return int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// resolveDay turns a day of the month into a day number, ok is false when
+// the day does not exist in that month.
+func resolveDay(year int, month time.Month, day int) (int, bool) {
+	last := daysIn(year, month)
+	if day < 0 {
+		day = last + day + 1
+	}
+	if day < 1 || day > last {
+		return 0, false
+	}
+	return dayNumber(year, month, day), true
+}
+
+// resolveWeekday finds the n-th weekday of a month, counting from the end
+// for negative n.
+func resolveWeekday(year int, month time.Month, weekday time.Weekday, n int) (int, bool) {
+	if n > 0 {
+		first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+		day := 1 + (int(weekday)-int(first)+7)%7 + (n-1)*7
+		return resolveDay(year, month, day)
+	}
+	last := daysIn(year, month)
+	lastWeekday := time.Date(year, month, last, 0, 0, 0, 0, time.UTC).Weekday()
+	day := last - (int(lastWeekday)-int(weekday)+7)%7 + (n+1)*7
+	return resolveDay(year, month, day)
+}
+
+// resolve returns the day number of spec in a period, which is a year for
+// yearly rules and a month count (year*12 + month - 1) for monthly rules.
+func (spec dateSpec) resolve(kind exceptionKind, period int) (int, bool) {
+	switch kind {
+	case calendarDate:
+		return resolveDay(spec.year, spec.month, spec.day)
+	case monthDate:
+		return resolveDay(period, spec.month, spec.day)
+	case monthWeekDay:
+		return resolveWeekday(period, spec.month, spec.weekday, spec.day)
+	}
+	year, month := period/12, time.Month(period%12+1)
+	if kind == monthDay {
+		return resolveDay(year, month, spec.day)
+	}
+	return resolveWeekday(year, month, spec.weekday, spec.day)
+}
+
+func (ex timeperiodException) matches(year int, month time.Month, day int) bool {
+	today := dayNumber(year, month, day)
+	var periods []int
+	switch ex.kind {
+	case calendarDate:
+		periods = []int{0}
+	case monthDate, monthWeekDay:
+		periods = []int{year - 1, year}
+	default:
+		current := year*12 + int(month) - 1
+		periods = []int{current - 1, current}
+	}
+	for _, period := range periods {
+		start, ok := ex.start.resolve(ex.kind, period)
+		if !ok {
+			continue
+		}
+		end, ok := ex.end.resolve(ex.kind, period)
+		if ex.openEnd {
+			end = today
+		}
+		if ok && end < start && ex.kind != calendarDate {
+			// ranges like "november 1 - february 1" end in the next period
+			end, ok = ex.end.resolve(ex.kind, period+1)
+		}
+		if !ok || today < start || today > end {
+			continue
+		}
+		if (today-start)%ex.skip == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *TimeperiodEvaluator) rangesOn(year int, month time.Month, day int) []secondRange {
+	for _, exception := range e.exceptions {
+		if exception.matches(year, month, day) {
+			return exception.ranges
+		}
+	}
+	weekday := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday()
+	return e.weekdays[weekday]
+}
+
+// Contains reports whether t lies within the timeperiod.
+func (e *TimeperiodEvaluator) Contains(t time.Time) bool {
+	local := t.In(e.location)
+	second := local.Hour()*3600 + local.Minute()*60 + local.Second()
+	inside := false
+	for _, r := range e.rangesOn(local.Date()) {
+		if second >= r.start && second < r.end {
+			inside = true
+			break
+		}
+	}
+	if !inside {
+		return false
+	}
+	for _, excluded := range e.excludes {
+		if excluded.Contains(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// boundaries adds the seconds of a day at which e or one of its excludes
+// may change state.
+func (e *TimeperiodEvaluator) boundaries(year int, month time.Month, day int, seconds map[int]bool) {
+	for _, r := range e.rangesOn(year, month, day) {
+		seconds[r.start] = true
+		seconds[r.end] = true
+	}
+	for _, excluded := range e.excludes {
+		excluded.boundaries(year, month, day, seconds)
+	}
+}
+
+// NextTransition returns the first moment after t at which Contains
+// changes its result. ok is false when the timeperiod does not change
+// within the next four years.
+func (e *TimeperiodEvaluator) NextTransition(t time.Time) (next time.Time, ok bool) {
+	current := e.Contains(t)
+	local := t.In(e.location)
+	for i := 0; i <= timeperiodSearchDays; i++ {
+		date := time.Date(local.Year(), local.Month(), local.Day()+i, 0, 0, 0, 0, e.location)
+		seconds := map[int]bool{0: true}
+		e.boundaries(date.Year(), date.Month(), date.Day(), seconds)
+		sorted := make([]int, 0, len(seconds))
+		for second := range seconds {
+			sorted = append(sorted, second)
+		}
+		sort.Ints(sorted)
+		for _, second := range sorted {
+			candidate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, second, 0, e.location)
+			if candidate.After(t) && e.Contains(candidate) != current {
+				return candidate, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,213 @@
+package thruk
+
+import (
+	"encoding/json"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+var workhours = Timeperiod{
+	TimeperiodName: "workhours",
+	Monday:         "09:00-17:00",
+	Tuesday:        "09:00-17:00",
+	Wednesday:      "09:00-17:00",
+	Thursday:       "09:00-17:00",
+	Friday:         "09:00-12:00,13:00-17:00",
+}
+
+func at(value string) time.Time {
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, time.UTC)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func newTestEvaluator(t *testing.T, tp Timeperiod, others ...Timeperiod) *TimeperiodEvaluator {
+	t.Helper()
+	evaluator, err := NewTimeperiodEvaluator(tp, time.UTC, others...)
+	assert.NilError(t, err)
+	return evaluator
+}
+
+func Test_Timeperiod_json(t *testing.T) {
+	t.Run("Exceptions are read from and written to unknown attributes", func(t *testing.T) {
+		var tp Timeperiod
+		err := json.Unmarshal([]byte(`{":TYPE":"timeperiod","timeperiod_name":"holidays","monday":"00:00-24:00","2026-12-25":"00:00-24:00","day 1 - 15":"09:00-10:00"}`), &tp)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, tp, Timeperiod{
+			TYPE:           "timeperiod",
+			TimeperiodName: "holidays",
+			Monday:         "00:00-24:00",
+			Exceptions:     map[string]string{"2026-12-25": "00:00-24:00", "day 1 - 15": "09:00-10:00"},
+		})
+
+		encoded, err := json.Marshal(tp)
+		assert.NilError(t, err)
+		var decoded Timeperiod
+		assert.NilError(t, json.Unmarshal(encoded, &decoded))
+		assert.DeepEqual(t, decoded, tp)
+	})
+	t.Run("Timeperiods without exceptions keep Exceptions nil", func(t *testing.T) {
+		var tp Timeperiod
+		err := json.Unmarshal([]byte(`{":TYPE":"timeperiod","timeperiod_name":"none","_CUSTOM":"x"}`), &tp)
+		assert.NilError(t, err)
+		assert.Assert(t, tp.Exceptions == nil)
+	})
+}
+
+func Test_TimeperiodEvaluator_Contains(t *testing.T) {
+	t.Run("Weekday time ranges", func(t *testing.T) {
+		e := newTestEvaluator(t, workhours)
+
+		assert.Assert(t, e.Contains(at("2026-10-19 09:00")))
+		assert.Assert(t, e.Contains(at("2026-10-19 16:59")))
+		assert.Assert(t, !e.Contains(at("2026-10-19 17:00")))
+		assert.Assert(t, !e.Contains(at("2026-10-23 12:30")))
+		assert.Assert(t, !e.Contains(at("2026-10-25 03:00")))
+	})
+	t.Run("Calendar date exception overrides the weekday", func(t *testing.T) {
+		tp := workhours
+		tp.Exceptions = map[string]string{"2026-12-25": "00:00-00:00", "2026-12-27": "10:00-11:00"}
+		e := newTestEvaluator(t, tp)
+
+		assert.Assert(t, !e.Contains(at("2026-12-25 10:00")))
+		assert.Assert(t, e.Contains(at("2026-12-27 10:30")))
+		assert.Assert(t, e.Contains(at("2026-12-28 10:30")))
+	})
+	t.Run("Calendar date range with skip interval", func(t *testing.T) {
+		e := newTestEvaluator(t, Timeperiod{Exceptions: map[string]string{"2026-01-01 - 2026-01-10 / 3": "00:00-24:00"}})
+
+		assert.Assert(t, e.Contains(at("2026-01-01 12:00")))
+		assert.Assert(t, !e.Contains(at("2026-01-02 12:00")))
+		assert.Assert(t, e.Contains(at("2026-01-04 12:00")))
+		assert.Assert(t, e.Contains(at("2026-01-10 12:00")))
+		assert.Assert(t, !e.Contains(at("2026-01-13 12:00")))
+	})
+	t.Run("Calendar date with skip interval continues forever", func(t *testing.T) {
+		e := newTestEvaluator(t, Timeperiod{Exceptions: map[string]string{"2026-01-01 / 7": "00:00-24:00"}})
+
+		assert.Assert(t, !e.Contains(at("2025-12-25 12:00")))
+		assert.Assert(t, e.Contains(at("2026-01-01 12:00")))
+		assert.Assert(t, !e.Contains(at("2026-01-02 12:00")))
+		assert.Assert(t, e.Contains(at("2026-01-08 12:00")))
+		assert.Assert(t, e.Contains(at("2027-12-30 12:00")))
+		assert.Assert(t, !e.Contains(at("2027-12-31 12:00")))
+	})
+	t.Run("Calendar date without skip interval is a single day", func(t *testing.T) {
+		e := newTestEvaluator(t, Timeperiod{Exceptions: map[string]string{"2026-01-01": "00:00-24:00"}})
+
+		assert.Assert(t, e.Contains(at("2026-01-01 12:00")))
+		assert.Assert(t, !e.Contains(at("2026-01-08 12:00")))
+	})
+	t.Run("Days of the month", func(t *testing.T) {
+		e := newTestEvaluator(t, Timeperiod{Exceptions: map[string]string{"day 1 - 15": "00:00-24:00", "day -1": "22:00-24:00"}})
+
+		assert.Assert(t, e.Contains(at("2026-03-15 12:00")))
+		assert.Assert(t, !e.Contains(at("2026-03-16 12:00")))
+		assert.Assert(t, e.Contains(at("2026-02-28 23:00")))
+		assert.Assert(t, !e.Contains(at("2026-02-27 23:00")))
+	})
+	t.Run("Month dates wrapping the year", func(t *testing.T) {
+		e := newTestEvaluator(t, Timeperiod{Exceptions: map[string]string{"december 24 - january 2": "00:00-24:00", "february -1": "00:00-24:00"}})
+
+		assert.Assert(t, e.Contains(at("2026-12-31 12:00")))
+		assert.Assert(t, e.Contains(at("2027-01-02 12:00")))
+		assert.Assert(t, !e.Contains(at("2027-01-03 12:00")))
+		assert.Assert(t, e.Contains(at("2028-02-29 12:00")))
+		assert.Assert(t, !e.Contains(at("2028-02-28 12:00")))
+	})
+	t.Run("Weekday of a given month and of every month", func(t *testing.T) {
+		e := newTestEvaluator(t, Timeperiod{Exceptions: map[string]string{
+			"monday 3 may":         "00:00-24:00",
+			"thursday -1 november": "00:00-24:00",
+			"friday 1":             "08:00-09:00",
+		}})
+
+		assert.Assert(t, e.Contains(at("2026-05-18 12:00")))
+		assert.Assert(t, !e.Contains(at("2026-05-11 12:00")))
+		assert.Assert(t, e.Contains(at("2026-11-26 12:00")))
+		assert.Assert(t, !e.Contains(at("2026-11-19 12:00")))
+		assert.Assert(t, e.Contains(at("2026-10-02 08:30")))
+		assert.Assert(t, !e.Contains(at("2026-10-09 08:30")))
+	})
+	t.Run("Excluded timeperiods are subtracted", func(t *testing.T) {
+		tp := workhours
+		tp.Exclude = []string{"lunch"}
+		lunch := Timeperiod{TimeperiodName: "lunch", Monday: "12:00-13:00"}
+		e := newTestEvaluator(t, tp, lunch)
+
+		assert.Assert(t, e.Contains(at("2026-10-19 11:59")))
+		assert.Assert(t, !e.Contains(at("2026-10-19 12:30")))
+		assert.Assert(t, e.Contains(at("2026-10-20 12:30")))
+	})
+	t.Run("Times are evaluated in the given location", func(t *testing.T) {
+		e, err := NewTimeperiodEvaluator(Timeperiod{Sunday: "00:00-06:00"}, time.FixedZone("UTC+2", 2*3600))
+		assert.NilError(t, err)
+
+		assert.Assert(t, e.Contains(at("2026-10-25 01:00")))
+		assert.Assert(t, !e.Contains(at("2026-10-25 05:00")))
+	})
+}
+
+func Test_TimeperiodEvaluator_errors(t *testing.T) {
+	t.Run("Unknown excluded timeperiod returns error", func(t *testing.T) {
+		_, err := NewTimeperiodEvaluator(Timeperiod{TimeperiodName: "a", Exclude: []string{"b"}}, time.UTC)
+		assert.Error(t, err, "timeperiod a: excluded timeperiod b not found")
+	})
+	t.Run("Excludes referring back return error", func(t *testing.T) {
+		a := Timeperiod{TimeperiodName: "a", Exclude: []string{"b"}}
+		b := Timeperiod{TimeperiodName: "b", Exclude: []string{"a"}}
+		_, err := NewTimeperiodEvaluator(a, time.UTC, a, b)
+		assert.Error(t, err, "timeperiod a excludes itself")
+	})
+	t.Run("Invalid definitions return error", func(t *testing.T) {
+		for _, tp := range []Timeperiod{
+			{Monday: "9-17"},
+			{Monday: "17:00-09:00"},
+			{Exceptions: map[string]string{"someday 1": "00:00-24:00"}},
+			{Exceptions: map[string]string{"day 1": ""}},
+			{Exceptions: map[string]string{"day 1 - monday 2": "00:00-24:00"}},
+		} {
+			_, err := NewTimeperiodEvaluator(tp, time.UTC)
+			assert.Assert(t, err != nil, "%v", tp)
+		}
+	})
+}
+
+func Test_TimeperiodEvaluator_NextTransition(t *testing.T) {
+	t.Run("Next transition from inside and outside the period", func(t *testing.T) {
+		e := newTestEvaluator(t, workhours)
+
+		next, ok := e.NextTransition(at("2026-10-23 10:00"))
+		assert.Assert(t, ok)
+		assert.Equal(t, next, at("2026-10-23 12:00"))
+
+		next, ok = e.NextTransition(at("2026-10-23 17:00"))
+		assert.Assert(t, ok)
+		assert.Equal(t, next, at("2026-10-26 09:00"))
+	})
+	t.Run("Ranges ending at midnight continue into the next day", func(t *testing.T) {
+		e := newTestEvaluator(t, Timeperiod{Monday: "22:00-24:00", Tuesday: "00:00-02:00"})
+
+		next, ok := e.NextTransition(at("2026-10-19 23:00"))
+		assert.Assert(t, ok)
+		assert.Equal(t, next, at("2026-10-20 02:00"))
+	})
+	t.Run("Next transition honors exclusions", func(t *testing.T) {
+		tp := workhours
+		tp.Exclude = []string{"lunch"}
+		e := newTestEvaluator(t, tp, Timeperiod{TimeperiodName: "lunch", Monday: "12:00-13:00"})
+
+		next, ok := e.NextTransition(at("2026-10-19 10:00"))
+		assert.Assert(t, ok)
+		assert.Equal(t, next, at("2026-10-19 12:00"))
+	})
+	t.Run("Periods that never change have no transition", func(t *testing.T) {
+		e := newTestEvaluator(t, Timeperiod{})
+
+		_, ok := e.NextTransition(at("2026-10-19 10:00"))
+		assert.Assert(t, !ok)
+	})
+}
@@ -0,0 +1,125 @@
+package thruk
+
+import (
+	"gotest.tools/assert"
+	"testing"
+)
+
+func Test_thruk_client_Timeperiod(t *testing.T) {
+	t.Run("Get timeperiod of empty id returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		object, err := thruk.GetTimeperiod("")
+		assert.Error(t, err, "[ERROR] invalid input")
+		assert.DeepEqual(t, object, Timeperiod{})
+	})
+	t.Run("Get timeperiod from id returns timeperiod", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		object, err := thruk.GetTimeperiod("341d4")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, object, Timeperiod{
+			FILE:           "/omd/sites/demo/etc/naemon/conf.d/thruk_templates.cfg:53",
+			ID:             "341d4",
+			PEERKEY:        "48f1c",
+			READONLY:       0,
+			TYPE:           "timeperiod",
+			Alias:          "24 Hours A Day, 7 Days A Week",
+			TimeperiodName: "thruk_24x7",
+			Monday:         "00:00-24:00",
+			Tuesday:        "00:00-24:00",
+			Wednesday:      "00:00-24:00",
+			Thursday:       "00:00-24:00",
+			Friday:         "00:00-24:00",
+			Saturday:       "00:00-24:00",
+			Sunday:         "00:00-24:00",
+		})
+	})
+	t.Run("Create timeperiod returns error when FILE and TYPE are empty", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.CreateTimeperiod(Timeperiod{})
+		assert.Error(t, err, "[ERROR] FILE and TYPE must not be empty")
+	})
+	t.Run("Create timeperiod returns nil error and ID on success", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateTimeperiod(Timeperiod{
+			FILE:           "test.cfg",
+			TYPE:           "timeperiod",
+			TimeperiodName: "test_timeperiod",
+		})
+		assert.NilError(t, err)
+		if id == "" {
+			t.Log("Create returned nil ID")
+			t.FailNow()
+		}
+		createdObject, err := thruk.GetTimeperiod(id)
+		assert.NilError(t, err)
+
+		assert.Equal(t, id, createdObject.ID)
+	})
+	t.Run("Get timeperiod by name returns timeperiod", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateTimeperiod(Timeperiod{
+			FILE:           "test.cfg",
+			TYPE:           "timeperiod",
+			TimeperiodName: "test_timeperiod_by_name",
+		})
+		assert.NilError(t, err)
+
+		object, err := thruk.GetTimeperiodByName("test_timeperiod_by_name")
+		assert.NilError(t, err)
+		assert.Equal(t, object.ID, id)
+	})
+	t.Run("Get timeperiod by unknown name returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		_, err := thruk.GetTimeperiodByName("not_existent")
+		assert.Error(t, err, "[ERROR] Object not found")
+	})
+	t.Run("Update timeperiod replaces its attributes", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, err := thruk.CreateTimeperiod(Timeperiod{
+			FILE:           "test.cfg",
+			TYPE:           "timeperiod",
+			TimeperiodName: "test_timeperiod_update",
+			Alias:          "before",
+		})
+		assert.NilError(t, err)
+		object, err := thruk.GetTimeperiod(id)
+		assert.NilError(t, err)
+
+		object.Alias = "after"
+		err = thruk.UpdateTimeperiod(object)
+		assert.NilError(t, err)
+
+		updated, err := thruk.GetTimeperiod(id)
+		assert.NilError(t, err)
+		assert.Equal(t, updated.Alias, "after")
+	})
+	t.Run("Update timeperiod without ID returns error", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		err := thruk.UpdateTimeperiod(Timeperiod{})
+		assert.Error(t, err, "[ERROR] ID must not be empty")
+	})
+	t.Run("Delete timeperiod must remove an object that exists", func(t *testing.T) {
+		thruk := startThrukServerAndGetClient(t)
+
+		id, _ := thruk.CreateTimeperiod(Timeperiod{
+			FILE: "test.cfg",
+			TYPE: "timeperiod",
+		})
+		if id == "" {
+			t.Fatal("failed to create object")
+		}
+		err := thruk.DeleteTimeperiod(id)
+		assert.NilError(t, err)
+		thruk.SaveConfigs()
+		_, err = thruk.GetTimeperiod(id)
+		assert.Error(t, err, "[ERROR] Object not found")
+	})
+}
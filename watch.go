@@ -0,0 +1,230 @@
+package thruk
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// WatchChange is a set of the attributes that changed between two polls.
+type WatchChange int
+
+const (
+	ChangeState WatchChange = 1 << iota
+	ChangeStateType
+	ChangeAcknowledged
+	ChangeDowntime
+	ChangeFlapping
+)
+
+func (c WatchChange) Has(change WatchChange) bool {
+	return c&change != 0
+}
+
+// ObjectState is the part of a host or service status the watcher
+// compares.
+type ObjectState struct {
+	State           int
+	StateType       int
+	Acknowledged    bool
+	InDowntime      bool
+	Flapping        bool
+	PluginOutput    string
+	LastStateChange time.Time
+	// HostGroups are the hostgroups of the host, which are not compared.
+	HostGroups []string
+}
+
+func (s ObjectState) changes(previous ObjectState) WatchChange {
+	var changes WatchChange
+	// a state that changed back between two polls still has a newer last
+	// state change
+	if s.State != previous.State || s.LastStateChange.After(previous.LastStateChange) {
+		changes |= ChangeState
+	}
+	if s.StateType != previous.StateType {
+		changes |= ChangeStateType
+	}
+	if s.Acknowledged != previous.Acknowledged {
+		changes |= ChangeAcknowledged
+	}
+	if s.InDowntime != previous.InDowntime {
+		changes |= ChangeDowntime
+	}
+	if s.Flapping != previous.Flapping {
+		changes |= ChangeFlapping
+	}
+	return changes
+}
+
+// StateEvent reports a change of a host, or of a service if
+// ServiceDescription is set.
+type StateEvent struct {
+	HostName           string
+	ServiceDescription string
+	PeerKey            string
+	Changes            WatchChange
+	// Previous is nil for changes that happened before the watch started,
+	// see WatchFilter.Since.
+	Previous *ObjectState
+	Current  ObjectState
+	// Time is the last state change for state changes and the time of the
+	// poll that noticed the change otherwise. Pass the Time of the last
+	// handled event as WatchFilter.Since to resume a watch.
+	Time time.Time
+}
+
+type WatchFilter struct {
+	// Hosts and Services filter the polled objects with Thruk's query
+	// parameter syntax, all objects are watched when nil.
+	Hosts    url.Values
+	Services url.Values
+	// SkipHosts and SkipServices disable polling of either kind.
+	SkipHosts    bool
+	SkipServices bool
+	// Since resumes a previous watch: objects whose state changed after
+	// Since are reported on the first poll with a nil Previous. Only state
+	// changes can be detected this way.
+	Since time.Time
+	// OnError is called with errors of failed polls. Polling continues
+	// after errors.
+	OnError func(error)
+}
+
+const DefaultWatchInterval = time.Minute
+
+type watchKey struct {
+	peer, host, service string
+}
+
+// Watch polls the host and service status every interval and sends an
+// event for every change of state, state type, acknowledgement, downtime
+// or flapping. The first poll only records the current state unless
+// filter.Since is set. Intervals of zero or less poll every
+// DefaultWatchInterval. The channel is closed when ctx is done.
+func (t Thruk) Watch(ctx context.Context, filter WatchFilter, interval time.Duration) <-chan StateEvent {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	events := make(chan StateEvent)
+	go func() {
+		defer close(events)
+		seen := map[watchKey]ObjectState{}
+		first := true
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			current, err := t.pollStates(ctx, filter)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if filter.OnError != nil {
+					filter.OnError(err)
+				}
+			} else {
+				now := time.Now()
+				for _, event := range diffStates(seen, current, first, filter.Since, now) {
+					select {
+					case events <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+				seen, first = current, false
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
+
+func (t Thruk) pollStates(ctx context.Context, filter WatchFilter) (map[watchKey]ObjectState, error) {
+	states := map[watchKey]ObjectState{}
+	if !filter.SkipHosts {
+		var hosts []HostStatus
+		if err := t.listAll(ctx, "hosts", filter.Hosts, &hosts); err != nil {
+			return nil, err
+		}
+		for _, host := range hosts {
+			states[watchKey{host.PeerKey, host.Name, ""}] = ObjectState{
+				State:           host.State,
+				StateType:       host.StateType,
+				Acknowledged:    host.Acknowledged != 0,
+				InDowntime:      host.ScheduledDowntimeDepth > 0,
+				Flapping:        host.IsFlapping != 0,
+				PluginOutput:    host.PluginOutput,
+				LastStateChange: time.Unix(host.LastStateChange, 0),
+				HostGroups:      host.Groups,
+			}
+		}
+	}
+	if !filter.SkipServices {
+		var services []ServiceStatus
+		if err := t.listAll(ctx, "services", filter.Services, &services); err != nil {
+			return nil, err
+		}
+		for _, service := range services {
+			states[watchKey{service.PeerKey, service.HostName, service.Description}] = ObjectState{
+				State:           service.State,
+				StateType:       service.StateType,
+				Acknowledged:    service.Acknowledged != 0,
+				InDowntime:      service.ScheduledDowntimeDepth > 0,
+				Flapping:        service.IsFlapping != 0,
+				PluginOutput:    service.PluginOutput,
+				LastStateChange: time.Unix(service.LastStateChange, 0),
+				HostGroups:      service.HostGroups,
+			}
+		}
+	}
+	return states, nil
+}
+
+// diffStates returns the events between two polls. Objects only reported
+// by a single poll produce no events, apart from changes after since on
+// the first poll.
+func diffStates(seen, current map[watchKey]ObjectState, first bool, since, now time.Time) []StateEvent {
+	var events []StateEvent
+	for key, state := range current {
+		event := StateEvent{HostName: key.host, ServiceDescription: key.service, PeerKey: key.peer, Current: state}
+		if first {
+			if since.IsZero() || !state.LastStateChange.After(since) {
+				continue
+			}
+			event.Changes, event.Time = ChangeState, state.LastStateChange
+			events = append(events, event)
+			continue
+		}
+		previous, ok := seen[key]
+		if !ok {
+			continue
+		}
+		event.Changes = state.changes(previous)
+		if event.Changes == 0 {
+			continue
+		}
+		event.Previous = &previous
+		event.Time = now
+		if event.Changes.Has(ChangeState) && state.LastStateChange.After(previous.LastStateChange) {
+			event.Time = state.LastStateChange
+		}
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		if a.HostName != b.HostName {
+			return a.HostName < b.HostName
+		}
+		return a.ServiceDescription < b.ServiceDescription
+	})
+	return events
+}
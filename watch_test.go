@@ -0,0 +1,160 @@
+package thruk
+
+import (
+	"context"
+	"encoding/json"
+	"gotest.tools/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeStatus struct {
+	sync.Mutex
+	hosts    []HostStatus
+	services []ServiceStatus
+	fail     bool
+}
+
+func (f *fakeStatus) set(update func()) {
+	f.Lock()
+	defer f.Unlock()
+	update()
+}
+
+func startFakeStatusServer(status *fakeStatus) (*Thruk, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status.Lock()
+		defer status.Unlock()
+		if status.fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/site/thruk/r/hosts":
+			json.NewEncoder(w).Encode(status.hosts)
+		case "/site/thruk/r/services":
+			json.NewEncoder(w).Encode(status.services)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return NewThruk(server.URL, "site", "user", "pass", false), server.Close
+}
+
+func nextEvent(t *testing.T, events <-chan StateEvent) StateEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		assert.Assert(t, ok, "events closed")
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event")
+	}
+	return StateEvent{}
+}
+
+func Test_Watch(t *testing.T) {
+	t.Run("Changes after the first poll are sent once", func(t *testing.T) {
+		status := &fakeStatus{
+			hosts:    []HostStatus{{Name: "web01", LastStateChange: 100}},
+			services: []ServiceStatus{{HostName: "web01", Description: "HTTP", LastStateChange: 100}},
+		}
+		thruk, closeServer := startFakeStatusServer(status)
+		defer closeServer()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events := thruk.Watch(ctx, WatchFilter{}, 10*time.Millisecond)
+		time.Sleep(30 * time.Millisecond)
+		status.set(func() {
+			status.services[0].State = StateCritical
+			status.services[0].LastStateChange = 200
+		})
+
+		event := nextEvent(t, events)
+		assert.Equal(t, event.HostName, "web01")
+		assert.Equal(t, event.ServiceDescription, "HTTP")
+		assert.Equal(t, event.Changes, ChangeState)
+		assert.Equal(t, event.Previous.State, StateOK)
+		assert.Equal(t, event.Current.State, StateCritical)
+		assert.Equal(t, event.Time, time.Unix(200, 0))
+
+		status.set(func() { status.hosts[0].Acknowledged = 1; status.hosts[0].ScheduledDowntimeDepth = 1 })
+		event = nextEvent(t, events)
+		assert.Equal(t, event.ServiceDescription, "")
+		assert.Equal(t, event.Changes, ChangeAcknowledged|ChangeDowntime)
+
+		cancel()
+		for range events {
+			t.Fatal("unexpected event")
+		}
+	})
+	t.Run("Watch resumes from a timestamp", func(t *testing.T) {
+		status := &fakeStatus{
+			hosts: []HostStatus{{Name: "old", LastStateChange: 100}, {Name: "new", State: 1, LastStateChange: 300}},
+		}
+		thruk, closeServer := startFakeStatusServer(status)
+		defer closeServer()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events := thruk.Watch(ctx, WatchFilter{SkipServices: true, Since: time.Unix(200, 0)}, time.Hour)
+		event := nextEvent(t, events)
+		assert.Equal(t, event.HostName, "new")
+		assert.Assert(t, event.Previous == nil)
+		assert.Equal(t, event.Time, time.Unix(300, 0))
+	})
+	t.Run("Poll errors are reported and polling continues", func(t *testing.T) {
+		status := &fakeStatus{fail: true, hosts: []HostStatus{{Name: "web01", LastStateChange: 100}}}
+		thruk, closeServer := startFakeStatusServer(status)
+		defer closeServer()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errs := make(chan error, 100)
+		events := thruk.Watch(ctx, WatchFilter{SkipServices: true, Since: time.Unix(0, 0), OnError: func(err error) { errs <- err }}, 10*time.Millisecond)
+		assert.Error(t, <-errs, "503 Service Unavailable")
+		status.set(func() { status.fail = false })
+
+		event := nextEvent(t, events)
+		assert.Equal(t, event.HostName, "web01")
+	})
+	t.Run("Intervals of zero or less use the default", func(t *testing.T) {
+		status := &fakeStatus{hosts: []HostStatus{{Name: "web01", LastStateChange: 100}}}
+		thruk, closeServer := startFakeStatusServer(status)
+		defer closeServer()
+
+		for _, interval := range []time.Duration{0, -time.Second} {
+			ctx, cancel := context.WithCancel(context.Background())
+			events := thruk.Watch(ctx, WatchFilter{SkipServices: true, Since: time.Unix(0, 0)}, interval)
+			event := nextEvent(t, events)
+			assert.Equal(t, event.HostName, "web01")
+			cancel()
+			for range events {
+			}
+		}
+	})
+}
+
+func Test_diffStates(t *testing.T) {
+	t.Run("A state that changed back between polls is reported", func(t *testing.T) {
+		key := watchKey{host: "web01"}
+		seen := map[watchKey]ObjectState{key: {LastStateChange: time.Unix(100, 0)}}
+		current := map[watchKey]ObjectState{key: {LastStateChange: time.Unix(150, 0)}}
+
+		events := diffStates(seen, current, false, time.Time{}, time.Unix(160, 0))
+		assert.Equal(t, len(events), 1)
+		assert.Equal(t, events[0].Changes, ChangeState)
+		assert.Equal(t, events[0].Time, time.Unix(150, 0))
+	})
+	t.Run("Unchanged and new objects are not reported", func(t *testing.T) {
+		state := ObjectState{State: 2, LastStateChange: time.Unix(100, 0)}
+		seen := map[watchKey]ObjectState{{host: "a"}: state}
+		current := map[watchKey]ObjectState{{host: "a"}: state, {host: "b"}: state}
+
+		assert.Equal(t, len(diffStates(seen, current, false, time.Time{}, time.Now())), 0)
+	})
+}
@@ -0,0 +1,287 @@
+package thruk
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	WebhookProblem         = "problem"
+	WebhookRecovery        = "recovery"
+	WebhookAcknowledgement = "acknowledgement"
+)
+
+// Host states, services use StateOK, StateWarning, StateCritical and
+// StateUnknown.
+const (
+	HostUp          = 0
+	HostDown        = 1
+	HostUnreachable = 2
+)
+
+// WebhookSignatureHeader carries the hex encoded HMAC-SHA256 of the body,
+// prefixed with "sha256=", when a Webhook has a Secret.
+const WebhookSignatureHeader = "X-Thruk-Signature"
+
+type Webhook struct {
+	Name string
+	URL  string
+	// Events are the kinds of events sent, all when empty.
+	Events []string
+	// Hostgroups limits the webhook to hosts, and services of hosts, in
+	// any of these hostgroups.
+	Hostgroups []string
+	// HostStates and ServiceStates limit the webhook to events of hosts in
+	// the given host states, e.g. HostDown, and of services in the given
+	// service states, e.g. StateCritical. When only one of them is set,
+	// events of the other object type are not sent. Recoveries match the
+	// state of the problem they recover from.
+	HostStates    []int
+	ServiceStates []int
+	// Template renders the body from a WebhookPayload with text/template,
+	// the payload is sent as JSON when empty. The function "json" encodes
+	// its argument.
+	Template    string
+	ContentType string
+	Headers     map[string]string
+	Secret      string
+	// Retries is the number of retries after failed deliveries, with
+	// RetryDelay doubling after each of them.
+	Retries    int
+	RetryDelay time.Duration
+}
+
+type WebhookPayload struct {
+	Event              string   `json:"event"`
+	HostName           string   `json:"host_name"`
+	ServiceDescription string   `json:"service_description,omitempty"`
+	State              int      `json:"state"`
+	StateName          string   `json:"state_name"`
+	StateType          int      `json:"state_type"`
+	PluginOutput       string   `json:"plugin_output"`
+	Acknowledged       bool     `json:"acknowledged"`
+	InDowntime         bool     `json:"in_downtime"`
+	HostGroups         []string `json:"host_groups,omitempty"`
+	PeerKey            string   `json:"peer_key,omitempty"`
+	Time               int64    `json:"time"`
+}
+
+var hostStateNames = []string{"UP", "DOWN", "UNREACHABLE"}
+var serviceStateNames = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+func stateName(service string, state int) string {
+	names := serviceStateNames
+	if service == "" {
+		names = hostStateNames
+	}
+	if state < 0 || state >= len(names) {
+		return "UNKNOWN"
+	}
+	return names[state]
+}
+
+// webhookEvents classifies a state event: hard non-OK states are problems,
+// a change from a hard non-OK state back to OK is a recovery and setting an
+// acknowledgement is an acknowledgement. Soft problems that recover send
+// nothing, as no problem was sent for them.
+func webhookEvents(event StateEvent) []string {
+	var kinds []string
+	current := event.Current
+	switch {
+	case current.State != StateOK && current.StateType == 1 && event.Changes.Has(ChangeState|ChangeStateType):
+		kinds = append(kinds, WebhookProblem)
+	case current.State == StateOK && event.Changes.Has(ChangeState) && event.Previous != nil && event.Previous.State != StateOK && event.Previous.StateType == 1:
+		kinds = append(kinds, WebhookRecovery)
+	}
+	if event.Changes.Has(ChangeAcknowledged) && current.Acknowledged {
+		kinds = append(kinds, WebhookAcknowledgement)
+	}
+	return kinds
+}
+
+type compiledWebhook struct {
+	Webhook
+	template *template.Template
+}
+
+func (w compiledWebhook) matches(kind string, event StateEvent) bool {
+	if len(w.Events) > 0 && !contains(w.Events, kind) {
+		return false
+	}
+	if len(w.Hostgroups) > 0 && !containsAny(event.Current.HostGroups, w.Hostgroups) {
+		return false
+	}
+	if len(w.HostStates) == 0 && len(w.ServiceStates) == 0 {
+		return true
+	}
+	states := w.HostStates
+	if event.ServiceDescription != "" {
+		states = w.ServiceStates
+	}
+	state := event.Current.State
+	if kind == WebhookRecovery {
+		state = event.Previous.State
+	}
+	return containsState(states, state)
+}
+
+// WebhookDispatcher posts state events to webhooks.
+type WebhookDispatcher struct {
+	Client *http.Client
+	// OnError is called by Run for deliveries that failed after all
+	// retries.
+	OnError  func(webhook Webhook, err error)
+	webhooks []compiledWebhook
+}
+
+func NewWebhookDispatcher(webhooks ...Webhook) (*WebhookDispatcher, error) {
+	d := &WebhookDispatcher{Client: &http.Client{Timeout: 30 * time.Second}}
+	for _, webhook := range webhooks {
+		if webhook.URL == "" {
+			return nil, ErrorInvalidInput
+		}
+		for _, kind := range webhook.Events {
+			if kind != WebhookProblem && kind != WebhookRecovery && kind != WebhookAcknowledgement {
+				return nil, fmt.Errorf("webhook %s: unknown event %s", webhook.Name, kind)
+			}
+		}
+		compiled := compiledWebhook{Webhook: webhook}
+		if webhook.Template != "" {
+			tmpl, err := template.New(webhook.Name).Funcs(template.FuncMap{"json": marshalString}).Parse(webhook.Template)
+			if err != nil {
+				return nil, fmt.Errorf("webhook %s: %v", webhook.Name, err)
+			}
+			compiled.template = tmpl
+		}
+		d.webhooks = append(d.webhooks, compiled)
+	}
+	return d, nil
+}
+
+func marshalString(v interface{}) (string, error) {
+	encoded, err := json.Marshal(v)
+	return string(encoded), err
+}
+
+// Dispatch sends the event to every matching webhook and returns the
+// errors of the failed deliveries.
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, event StateEvent) error {
+	var failed []string
+	d.dispatch(ctx, event, func(webhook Webhook, err error) {
+		failed = append(failed, webhook.Name+": "+err.Error())
+	})
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func (d *WebhookDispatcher) dispatch(ctx context.Context, event StateEvent, onError func(Webhook, error)) {
+	for _, kind := range webhookEvents(event) {
+		payload := WebhookPayload{
+			Event:              kind,
+			HostName:           event.HostName,
+			ServiceDescription: event.ServiceDescription,
+			State:              event.Current.State,
+			StateName:          stateName(event.ServiceDescription, event.Current.State),
+			StateType:          event.Current.StateType,
+			PluginOutput:       event.Current.PluginOutput,
+			Acknowledged:       event.Current.Acknowledged,
+			InDowntime:         event.Current.InDowntime,
+			HostGroups:         event.Current.HostGroups,
+			PeerKey:            event.PeerKey,
+			Time:               event.Time.Unix(),
+		}
+		for _, webhook := range d.webhooks {
+			if !webhook.matches(kind, event) {
+				continue
+			}
+			if err := d.deliver(ctx, webhook, payload); err != nil && onError != nil {
+				onError(webhook.Webhook, err)
+			}
+		}
+	}
+}
+
+// Run dispatches events until the channel is closed, e.g. the channel of
+// Watch.
+func (d *WebhookDispatcher) Run(ctx context.Context, events <-chan StateEvent) {
+	for event := range events {
+		d.dispatch(ctx, event, d.OnError)
+	}
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, webhook compiledWebhook, payload WebhookPayload) error {
+	var body []byte
+	var err error
+	if webhook.template != nil {
+		var buf bytes.Buffer
+		err = webhook.template.Execute(&buf, payload)
+		body = buf.Bytes()
+	} else {
+		body, err = json.Marshal(payload)
+	}
+	if err != nil {
+		return err
+	}
+
+	delay := webhook.RetryDelay
+	if delay == 0 {
+		delay = time.Second
+	}
+	for attempt := 0; ; attempt++ {
+		retry, err := d.post(ctx, webhook, body)
+		if err == nil || !retry || attempt >= webhook.Retries {
+			return err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// post sends body once and reports whether a failure is worth a retry.
+func (d *WebhookDispatcher) post(ctx context.Context, webhook compiledWebhook, body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	contentType := webhook.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range webhook.Headers {
+		req.Header.Set(name, value)
+	}
+	if webhook.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(webhook.Secret))
+		mac.Write(body)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, errors.New(resp.Status)
+	}
+	return false, nil
+}
@@ -0,0 +1,201 @@
+package thruk
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"gotest.tools/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+type webhookReceiver struct {
+	sync.Mutex
+	received []receivedWebhook
+	statuses []int
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	r.Lock()
+	defer r.Unlock()
+	r.received = append(r.received, receivedWebhook{header: req.Header, body: body})
+	if len(r.statuses) > 0 {
+		w.WriteHeader(r.statuses[0])
+		r.statuses = r.statuses[1:]
+	}
+}
+
+var problemEvent = StateEvent{
+	HostName:           "web01",
+	ServiceDescription: "HTTP",
+	Changes:            ChangeState,
+	Previous:           &ObjectState{State: StateOK, StateType: 1},
+	Current:            ObjectState{State: StateCritical, StateType: 1, PluginOutput: "connection refused", HostGroups: []string{"web"}},
+	Time:               time.Unix(1000, 0),
+}
+
+func Test_webhookEvents(t *testing.T) {
+	t.Run("Events are classified", func(t *testing.T) {
+		assert.DeepEqual(t, webhookEvents(problemEvent), []string{WebhookProblem})
+
+		soft := problemEvent
+		soft.Current.StateType = 0
+		assert.Equal(t, len(webhookEvents(soft)), 0)
+
+		recovery := StateEvent{Changes: ChangeState, Previous: &ObjectState{State: StateCritical, StateType: 1}, Current: ObjectState{State: StateOK, StateType: 1}}
+		assert.DeepEqual(t, webhookEvents(recovery), []string{WebhookRecovery})
+
+		softRecovery := StateEvent{Changes: ChangeState | ChangeStateType, Previous: &ObjectState{State: StateCritical, StateType: 0}, Current: ObjectState{State: StateOK, StateType: 1}}
+		assert.Equal(t, len(webhookEvents(softRecovery)), 0)
+
+		ack := StateEvent{Changes: ChangeAcknowledged, Previous: &ObjectState{State: StateCritical, StateType: 1}, Current: ObjectState{State: StateCritical, StateType: 1, Acknowledged: true}}
+		assert.DeepEqual(t, webhookEvents(ack), []string{WebhookAcknowledgement})
+	})
+}
+
+func Test_WebhookDispatcher(t *testing.T) {
+	t.Run("Payload is posted as signed JSON", func(t *testing.T) {
+		receiver := &webhookReceiver{}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+
+		d, err := NewWebhookDispatcher(Webhook{Name: "chat", URL: server.URL, Secret: "s3cret", Headers: map[string]string{"X-Token": "abc"}})
+		assert.NilError(t, err)
+		assert.NilError(t, d.Dispatch(context.Background(), problemEvent))
+
+		assert.Equal(t, len(receiver.received), 1)
+		received := receiver.received[0]
+		var payload WebhookPayload
+		assert.NilError(t, json.Unmarshal(received.body, &payload))
+		assert.DeepEqual(t, payload, WebhookPayload{
+			Event:              WebhookProblem,
+			HostName:           "web01",
+			ServiceDescription: "HTTP",
+			State:              StateCritical,
+			StateName:          "CRITICAL",
+			StateType:          1,
+			PluginOutput:       "connection refused",
+			HostGroups:         []string{"web"},
+			Time:               1000,
+		})
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(received.body)
+		assert.Equal(t, received.header.Get(WebhookSignatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		assert.Equal(t, received.header.Get("X-Token"), "abc")
+		assert.Equal(t, received.header.Get("Content-Type"), "application/json")
+	})
+	t.Run("Templates render the body", func(t *testing.T) {
+		receiver := &webhookReceiver{}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+
+		d, err := NewWebhookDispatcher(Webhook{URL: server.URL, Template: `{"text": {{json (printf "%s/%s is %s" .HostName .ServiceDescription .StateName)}}}`})
+		assert.NilError(t, err)
+		assert.NilError(t, d.Dispatch(context.Background(), problemEvent))
+		assert.Equal(t, string(receiver.received[0].body), `{"text": "web01/HTTP is CRITICAL"}`)
+	})
+	t.Run("Filters select the webhooks", func(t *testing.T) {
+		receiver := &webhookReceiver{}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+
+		d, err := NewWebhookDispatcher(
+			Webhook{Name: "db", URL: server.URL + "/db", Hostgroups: []string{"db"}},
+			Webhook{Name: "warnings", URL: server.URL + "/warnings", ServiceStates: []int{StateWarning}},
+			Webhook{Name: "recoveries", URL: server.URL + "/recoveries", Events: []string{WebhookRecovery}},
+			Webhook{Name: "web", URL: server.URL + "/web", Hostgroups: []string{"web"}, ServiceStates: []int{StateCritical}},
+		)
+		assert.NilError(t, err)
+		assert.NilError(t, d.Dispatch(context.Background(), problemEvent))
+		assert.Equal(t, len(receiver.received), 1)
+	})
+	t.Run("State filters match recoveries by the problem state", func(t *testing.T) {
+		receiver := &webhookReceiver{}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+
+		d, err := NewWebhookDispatcher(Webhook{Name: "critical", URL: server.URL, ServiceStates: []int{StateCritical}})
+		assert.NilError(t, err)
+		recovery := problemEvent
+		recovery.Previous, recovery.Current = &problemEvent.Current, ObjectState{State: StateOK, StateType: 1}
+		warningRecovery := recovery
+		warningRecovery.Previous = &ObjectState{State: StateWarning, StateType: 1}
+		assert.NilError(t, d.Dispatch(context.Background(), problemEvent))
+		assert.NilError(t, d.Dispatch(context.Background(), recovery))
+		assert.NilError(t, d.Dispatch(context.Background(), warningRecovery))
+
+		assert.Equal(t, len(receiver.received), 2)
+		var payload WebhookPayload
+		assert.NilError(t, json.Unmarshal(receiver.received[1].body, &payload))
+		assert.Equal(t, payload.Event, WebhookRecovery)
+	})
+	t.Run("Host and service states are filtered separately", func(t *testing.T) {
+		receiver := &webhookReceiver{}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+
+		d, err := NewWebhookDispatcher(
+			Webhook{Name: "warnings", URL: server.URL + "/warnings", ServiceStates: []int{StateWarning}},
+			Webhook{Name: "down", URL: server.URL + "/down", HostStates: []int{HostDown}},
+		)
+		assert.NilError(t, err)
+		hostDown := StateEvent{
+			HostName: "web01", Changes: ChangeState,
+			Previous: &ObjectState{State: HostUp, StateType: 1},
+			Current:  ObjectState{State: HostDown, StateType: 1},
+		}
+		serviceWarning := problemEvent
+		serviceWarning.Current.State = StateWarning
+		assert.NilError(t, d.Dispatch(context.Background(), hostDown))
+		assert.NilError(t, d.Dispatch(context.Background(), serviceWarning))
+
+		assert.Equal(t, len(receiver.received), 2)
+		paths := []string{}
+		for _, received := range receiver.received {
+			var payload WebhookPayload
+			assert.NilError(t, json.Unmarshal(received.body, &payload))
+			paths = append(paths, payload.HostName+"/"+payload.ServiceDescription+" "+payload.StateName)
+		}
+		assert.DeepEqual(t, paths, []string{"web01/ DOWN", "web01/HTTP WARNING"})
+	})
+	t.Run("Failed deliveries are retried", func(t *testing.T) {
+		receiver := &webhookReceiver{statuses: []int{503, 502, 200}}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+
+		d, err := NewWebhookDispatcher(Webhook{URL: server.URL, Retries: 2, RetryDelay: time.Millisecond})
+		assert.NilError(t, err)
+		assert.NilError(t, d.Dispatch(context.Background(), problemEvent))
+		assert.Equal(t, len(receiver.received), 3)
+	})
+	t.Run("Client errors are not retried and returned", func(t *testing.T) {
+		receiver := &webhookReceiver{statuses: []int{400}}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+
+		d, err := NewWebhookDispatcher(Webhook{Name: "ticket", URL: server.URL, Retries: 3, RetryDelay: time.Millisecond})
+		assert.NilError(t, err)
+		assert.Error(t, d.Dispatch(context.Background(), problemEvent), "ticket: 400 Bad Request")
+		assert.Equal(t, len(receiver.received), 1)
+	})
+	t.Run("Invalid webhooks are rejected", func(t *testing.T) {
+		_, err := NewWebhookDispatcher(Webhook{URL: "http://localhost", Events: []string{"flapping"}})
+		assert.Assert(t, err != nil)
+		_, err = NewWebhookDispatcher(Webhook{URL: "http://localhost", Template: "{{"})
+		assert.Assert(t, err != nil)
+		_, err = NewWebhookDispatcher(Webhook{})
+		assert.Equal(t, err, ErrorInvalidInput)
+	})
+}